@@ -0,0 +1,448 @@
+/*
+Copyright 2019 The Kubeflow community.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	rayv2 "github.com/kubeflow/ray-operator/api/v2"
+)
+
+// AnnotationWorkerGroupAutoscaling keeps the autoscaling of the v2 worker groups which
+// v1 cannot represent, i.e. of the groups other than the only group named default. It
+// is a JSON object from the name of the group to its autoscaling, and it is removed
+// when the Ray is converted back to v2.
+const AnnotationWorkerGroupAutoscaling = "ray.kubeflow.org/worker-group-autoscaling"
+
+var _ conversion.Convertible = &Ray{}
+
+// ConvertTo converts the Ray to the hub version v2. spec.worker and spec.autoscaling
+// are the only worker group named default in v2, and spec.head.serviceType and
+// spec.head.serviceAnnotations are spec.head.service. The other fields of both versions
+// map one to one. The pointers, slices and maps are shared with the source.
+func (r *Ray) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*rayv2.Ray)
+	dst.ObjectMeta = r.ObjectMeta
+	autoscaling, err := popWorkerGroupAutoscaling(&dst.ObjectMeta)
+	if err != nil {
+		return err
+	}
+
+	dst.Spec.RayVersion = r.Spec.RayVersion
+	dst.Spec.RayStartParams = r.Spec.RayStartParams
+	dst.Spec.Head = convertHeadSpecTo(r.Spec.Head)
+	dst.Spec.WorkerGroups = convertWorkerGroupsTo(&r.Spec, autoscaling)
+	dst.Spec.Termination = nil
+	if t := r.Spec.Termination; t != nil {
+		dst.Spec.Termination = &rayv2.TerminationSpec{
+			GracePeriodSeconds: t.GracePeriodSeconds,
+			PreDeleteHook:      t.PreDeleteHook,
+		}
+	}
+	dst.Spec.Scheduling = convertSchedulingSpecTo(r.Spec.Scheduling)
+	dst.Spec.HeadFaultTolerance = (*rayv2.HeadFaultToleranceSpec)(r.Spec.HeadFaultTolerance)
+
+	convertRayStatusTo(&r.Status, &dst.Status)
+	return nil
+}
+
+// ConvertFrom converts the Ray from the hub version v2.
+func (r *Ray) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*rayv2.Ray)
+	r.ObjectMeta = src.ObjectMeta
+
+	r.Spec.RayVersion = src.Spec.RayVersion
+	r.Spec.RayStartParams = src.Spec.RayStartParams
+	r.Spec.Head = convertHeadSpecFrom(src.Spec.Head)
+	if err := r.convertWorkerGroupsFrom(src.Spec.WorkerGroups); err != nil {
+		return err
+	}
+	r.Spec.Termination = nil
+	if t := src.Spec.Termination; t != nil {
+		r.Spec.Termination = &TerminationSpec{
+			GracePeriodSeconds: t.GracePeriodSeconds,
+			PreDeleteHook:      t.PreDeleteHook,
+		}
+	}
+	r.Spec.Scheduling = convertSchedulingSpecFrom(src.Spec.Scheduling)
+	r.Spec.HeadFaultTolerance = (*HeadFaultToleranceSpec)(src.Spec.HeadFaultTolerance)
+
+	convertRayStatusFrom(&src.Status, &r.Status)
+	return nil
+}
+
+func convertHeadSpecTo(head *HeadSpec) *rayv2.HeadSpec {
+	if head == nil {
+		return nil
+	}
+	return &rayv2.HeadSpec{
+		ReplicaSpec:  convertReplicaSpecTo(head.ReplicaSpec),
+		WorkloadKind: rayv2.HeadWorkloadKind(head.WorkloadKind),
+		Service: rayv2.HeadServiceSpec{
+			Type:        head.ServiceType,
+			Annotations: head.ServiceAnnotations,
+		},
+		Ingress: (*rayv2.HeadIngressSpec)(head.Ingress),
+	}
+}
+
+func convertHeadSpecFrom(head *rayv2.HeadSpec) *HeadSpec {
+	if head == nil {
+		return nil
+	}
+	return &HeadSpec{
+		ReplicaSpec:        convertReplicaSpecFrom(head.ReplicaSpec),
+		WorkloadKind:       HeadWorkloadKind(head.WorkloadKind),
+		ServiceType:        head.Service.Type,
+		ServiceAnnotations: head.Service.Annotations,
+		Ingress:            (*HeadIngressSpec)(head.Ingress),
+	}
+}
+
+// convertWorkerGroupsTo converts the workers of the spec to the v2 worker groups. The
+// workers described by spec.worker are the only group named default, unless they are
+// not set at all. The autoscaling of the other groups is restored from the annotation.
+func convertWorkerGroupsTo(spec *RaySpec, autoscaling map[string]*rayv2.AutoscalingSpec) []rayv2.WorkerGroupSpec {
+	if len(spec.WorkerGroups) == 0 {
+		if isEmptyReplicaSpec(spec.Worker) && spec.Autoscaling == nil {
+			return nil
+		}
+		return []rayv2.WorkerGroupSpec{
+			{
+				Name:        DefaultWorkerGroupName,
+				ReplicaSpec: convertReplicaSpecTo(spec.Worker),
+				Autoscaling: convertAutoscalingSpecTo(spec.Autoscaling),
+			},
+		}
+	}
+	groups := make([]rayv2.WorkerGroupSpec, len(spec.WorkerGroups))
+	for i, g := range spec.WorkerGroups {
+		groups[i] = rayv2.WorkerGroupSpec{
+			Name:         g.Name,
+			ReplicaSpec:  convertReplicaSpecTo(g.ReplicaSpec),
+			RayResources: g.RayResources,
+			Autoscaling:  autoscaling[g.Name],
+		}
+	}
+	return groups
+}
+
+// convertWorkerGroupsFrom converts the v2 worker groups to the workers of the Ray. The
+// only group named default without custom resources is spec.worker and spec.autoscaling,
+// the other groups are spec.workerGroups, and their autoscaling is kept in the annotation.
+func (r *Ray) convertWorkerGroupsFrom(groups []rayv2.WorkerGroupSpec) error {
+	r.Spec.Worker = ReplicaSpec{}
+	r.Spec.Autoscaling = nil
+	r.Spec.WorkerGroups = nil
+	if isDefaultWorkerGroup(groups) {
+		r.Spec.Worker = convertReplicaSpecFrom(groups[0].ReplicaSpec)
+		r.Spec.Autoscaling = convertAutoscalingSpecFrom(groups[0].Autoscaling)
+		return nil
+	}
+	if groups == nil {
+		return nil
+	}
+
+	r.Spec.WorkerGroups = make([]WorkerGroupSpec, len(groups))
+	autoscaling := map[string]*rayv2.AutoscalingSpec{}
+	for i, g := range groups {
+		r.Spec.WorkerGroups[i] = WorkerGroupSpec{
+			Name:         g.Name,
+			ReplicaSpec:  convertReplicaSpecFrom(g.ReplicaSpec),
+			RayResources: g.RayResources,
+		}
+		if g.Autoscaling != nil {
+			autoscaling[g.Name] = g.Autoscaling
+		}
+	}
+	if len(autoscaling) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(autoscaling)
+	if err != nil {
+		return err
+	}
+	// The annotations are copied since they are shared with the source.
+	annotations := make(map[string]string, len(r.Annotations)+1)
+	for k, v := range r.Annotations {
+		annotations[k] = v
+	}
+	annotations[AnnotationWorkerGroupAutoscaling] = string(data)
+	r.Annotations = annotations
+	return nil
+}
+
+// isDefaultWorkerGroup returns true if the groups are the only group named default which
+// spec.worker and spec.autoscaling can represent.
+func isDefaultWorkerGroup(groups []rayv2.WorkerGroupSpec) bool {
+	if len(groups) != 1 {
+		return false
+	}
+	g := groups[0]
+	return g.Name == DefaultWorkerGroupName && len(g.RayResources) == 0 &&
+		(g.Replicas != nil || g.Template != nil || g.Probes != nil || g.Autoscaling != nil)
+}
+
+func isEmptyReplicaSpec(spec ReplicaSpec) bool {
+	return spec.Replicas == nil && spec.Template == nil && spec.Probes == nil
+}
+
+// popWorkerGroupAutoscaling removes the annotation of the worker group autoscaling from
+// the metadata, and returns the autoscaling of the groups in it.
+func popWorkerGroupAutoscaling(meta *metav1.ObjectMeta) (map[string]*rayv2.AutoscalingSpec, error) {
+	data, ok := meta.Annotations[AnnotationWorkerGroupAutoscaling]
+	if !ok {
+		return nil, nil
+	}
+	autoscaling := map[string]*rayv2.AutoscalingSpec{}
+	if err := json.Unmarshal([]byte(data), &autoscaling); err != nil {
+		return nil, fmt.Errorf("invalid annotation %s: %v", AnnotationWorkerGroupAutoscaling, err)
+	}
+	// The annotations are copied since they are shared with the source.
+	var annotations map[string]string
+	for k, v := range meta.Annotations {
+		if k == AnnotationWorkerGroupAutoscaling {
+			continue
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[k] = v
+	}
+	meta.Annotations = annotations
+	return autoscaling, nil
+}
+
+func convertReplicaSpecTo(spec ReplicaSpec) rayv2.ReplicaSpec {
+	return rayv2.ReplicaSpec{
+		Replicas: spec.Replicas,
+		Template: spec.Template,
+		Probes:   (*rayv2.ProbeSpec)(spec.Probes),
+	}
+}
+
+func convertReplicaSpecFrom(spec rayv2.ReplicaSpec) ReplicaSpec {
+	return ReplicaSpec{
+		Replicas: spec.Replicas,
+		Template: spec.Template,
+		Probes:   (*ProbeSpec)(spec.Probes),
+	}
+}
+
+func convertAutoscalingSpecTo(spec *AutoscalingSpec) *rayv2.AutoscalingSpec {
+	if spec == nil {
+		return nil
+	}
+	return &rayv2.AutoscalingSpec{
+		MinReplicas:        spec.MinReplicas,
+		MaxReplicas:        spec.MaxReplicas,
+		UpscalingSpeed:     spec.UpscalingSpeed,
+		IdleTimeoutSeconds: spec.IdleTimeoutSeconds,
+	}
+}
+
+func convertAutoscalingSpecFrom(spec *rayv2.AutoscalingSpec) *AutoscalingSpec {
+	if spec == nil {
+		return nil
+	}
+	return &AutoscalingSpec{
+		MinReplicas:        spec.MinReplicas,
+		MaxReplicas:        spec.MaxReplicas,
+		UpscalingSpeed:     spec.UpscalingSpeed,
+		IdleTimeoutSeconds: spec.IdleTimeoutSeconds,
+	}
+}
+
+func convertSchedulingSpecTo(spec *SchedulingSpec) *rayv2.SchedulingSpec {
+	if spec == nil {
+		return nil
+	}
+	return &rayv2.SchedulingSpec{
+		Provider:               rayv2.SchedulingProvider(spec.Provider),
+		SchedulerName:          spec.SchedulerName,
+		MinWorkers:             spec.MinWorkers,
+		Queue:                  spec.Queue,
+		ScheduleTimeoutSeconds: spec.ScheduleTimeoutSeconds,
+	}
+}
+
+func convertSchedulingSpecFrom(spec *rayv2.SchedulingSpec) *SchedulingSpec {
+	if spec == nil {
+		return nil
+	}
+	return &SchedulingSpec{
+		Provider:               SchedulingProvider(spec.Provider),
+		SchedulerName:          spec.SchedulerName,
+		MinWorkers:             spec.MinWorkers,
+		Queue:                  spec.Queue,
+		ScheduleTimeoutSeconds: spec.ScheduleTimeoutSeconds,
+	}
+}
+
+func convertRayStatusTo(status *RayStatus, dst *rayv2.RayStatus) {
+	dst.Phase = rayv2.RayPhase(status.Phase)
+	dst.Message = status.Message
+	dst.HeadServiceIP = status.HeadServiceIP
+	dst.DashboardURL = status.DashboardURL
+	dst.Endpoints = nil
+	if status.Endpoints != nil {
+		dst.Endpoints = make([]rayv2.EndpointStatus, len(status.Endpoints))
+		for i, e := range status.Endpoints {
+			dst.Endpoints[i] = rayv2.EndpointStatus{
+				Name: e.Name,
+				Type: rayv2.EndpointType(e.Type),
+				Host: e.Host,
+				Port: e.Port,
+			}
+		}
+	}
+	dst.Head = rayv2.ReplicaStatus(status.Head)
+	dst.WorkerGroups = nil
+	if status.WorkerGroups != nil {
+		dst.WorkerGroups = make([]rayv2.WorkerGroupStatus, len(status.WorkerGroups))
+		for i, g := range status.WorkerGroups {
+			dst.WorkerGroups[i] = rayv2.WorkerGroupStatus{
+				Name:          g.Name,
+				ReplicaStatus: rayv2.ReplicaStatus(g.ReplicaStatus),
+				Conditions:    convertConditionsTo(g.Conditions),
+			}
+		}
+	}
+	dst.WorkerReplicas = status.WorkerReplicas
+	dst.WorkerSelector = status.WorkerSelector
+	dst.Autoscaler = nil
+	if a := status.Autoscaler; a != nil {
+		autoscaler := rayv2.AutoscalerStatus(*a)
+		dst.Autoscaler = &autoscaler
+	}
+	dst.ClusterResources = nil
+	if c := status.ClusterResources; c != nil {
+		resources := rayv2.ClusterResourcesStatus(*c)
+		dst.ClusterResources = &resources
+	}
+	dst.Scheduling = nil
+	if s := status.Scheduling; s != nil {
+		scheduling := rayv2.SchedulingStatus{
+			Provider:     rayv2.SchedulingProvider(s.Provider),
+			PodGroupName: s.PodGroupName,
+			MinMember:    s.MinMember,
+			Phase:        s.Phase,
+		}
+		dst.Scheduling = &scheduling
+	}
+	dst.Conditions = convertConditionsTo(status.Conditions)
+	dst.StartTime = status.StartTime
+	dst.ObservedGeneration = status.ObservedGeneration
+	dst.LastReconcileTime = status.LastReconcileTime
+}
+
+func convertRayStatusFrom(status *rayv2.RayStatus, dst *RayStatus) {
+	dst.Phase = RayPhase(status.Phase)
+	dst.Message = status.Message
+	dst.HeadServiceIP = status.HeadServiceIP
+	dst.DashboardURL = status.DashboardURL
+	dst.Endpoints = nil
+	if status.Endpoints != nil {
+		dst.Endpoints = make([]EndpointStatus, len(status.Endpoints))
+		for i, e := range status.Endpoints {
+			dst.Endpoints[i] = EndpointStatus{
+				Name: e.Name,
+				Type: EndpointType(e.Type),
+				Host: e.Host,
+				Port: e.Port,
+			}
+		}
+	}
+	dst.Head = ReplicaStatus(status.Head)
+	dst.WorkerGroups = nil
+	if status.WorkerGroups != nil {
+		dst.WorkerGroups = make([]WorkerGroupStatus, len(status.WorkerGroups))
+		for i, g := range status.WorkerGroups {
+			dst.WorkerGroups[i] = WorkerGroupStatus{
+				Name:          g.Name,
+				ReplicaStatus: ReplicaStatus(g.ReplicaStatus),
+				Conditions:    convertConditionsFrom(g.Conditions),
+			}
+		}
+	}
+	dst.WorkerReplicas = status.WorkerReplicas
+	dst.WorkerSelector = status.WorkerSelector
+	dst.Autoscaler = nil
+	if a := status.Autoscaler; a != nil {
+		autoscaler := AutoscalerStatus(*a)
+		dst.Autoscaler = &autoscaler
+	}
+	dst.ClusterResources = nil
+	if c := status.ClusterResources; c != nil {
+		resources := ClusterResourcesStatus(*c)
+		dst.ClusterResources = &resources
+	}
+	dst.Scheduling = nil
+	if s := status.Scheduling; s != nil {
+		scheduling := SchedulingStatus{
+			Provider:     SchedulingProvider(s.Provider),
+			PodGroupName: s.PodGroupName,
+			MinMember:    s.MinMember,
+			Phase:        s.Phase,
+		}
+		dst.Scheduling = &scheduling
+	}
+	dst.Conditions = convertConditionsFrom(status.Conditions)
+	dst.StartTime = status.StartTime
+	dst.ObservedGeneration = status.ObservedGeneration
+	dst.LastReconcileTime = status.LastReconcileTime
+}
+
+func convertConditionsTo(conditions []RayCondition) []rayv2.RayCondition {
+	if conditions == nil {
+		return nil
+	}
+	out := make([]rayv2.RayCondition, len(conditions))
+	for i, c := range conditions {
+		out[i] = rayv2.RayCondition{
+			Type:               rayv2.RayConditionType(c.Type),
+			Status:             c.Status,
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastUpdateTime:     c.LastUpdateTime,
+			LastTransitionTime: c.LastTransitionTime,
+		}
+	}
+	return out
+}
+
+func convertConditionsFrom(conditions []rayv2.RayCondition) []RayCondition {
+	if conditions == nil {
+		return nil
+	}
+	out := make([]RayCondition, len(conditions))
+	for i, c := range conditions {
+		out[i] = RayCondition{
+			Type:               RayConditionType(c.Type),
+			Status:             c.Status,
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastUpdateTime:     c.LastUpdateTime,
+			LastTransitionTime: c.LastTransitionTime,
+		}
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 The Kubeflow community.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/diff"
+
+	rayv2 "github.com/kubeflow/ray-operator/api/v2"
+)
+
+const fuzzIterations = 200
+
+func newFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.NewWithSeed(seed).NilChance(0.3).NumElements(0, 2).MaxDepth(12).Funcs(
+		// Quantity only has unexported fields, which are never filled by the fuzzer.
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewQuantity(c.Int63n(1000), resource.DecimalSI)
+		},
+		// Only the workers accepted by the validator are fuzzed, i.e. spec.worker and
+		// spec.autoscaling are not set together with spec.workerGroups, and no group is
+		// named default, which is the name of the group of spec.worker in v2.
+		func(s *RaySpec, c fuzz.Continue) {
+			c.FuzzNoCustom(s)
+			if len(s.WorkerGroups) != 0 {
+				s.Worker = ReplicaSpec{}
+				s.Autoscaling = nil
+			}
+			for i := range s.WorkerGroups {
+				if s.WorkerGroups[i].Name == DefaultWorkerGroupName {
+					s.WorkerGroups[i].Name += "-0"
+				}
+			}
+		},
+	)
+}
+
+func TestRayRoundTripFromSpoke(t *testing.T) {
+	f := newFuzzer(1)
+	for i := 0; i < fuzzIterations; i++ {
+		src := &Ray{}
+		f.Fuzz(src)
+		hub := &rayv2.Ray{}
+		if err := src.DeepCopy().ConvertTo(hub); err != nil {
+			t.Fatalf("failed to convert to v2: %v", err)
+		}
+		dst := &Ray{}
+		if err := dst.ConvertFrom(hub); err != nil {
+			t.Fatalf("failed to convert from v2: %v", err)
+		}
+		// TypeMeta is set by the conversion webhook, not by the conversion functions.
+		dst.TypeMeta = src.TypeMeta
+		if !equality.Semantic.DeepEqual(src, dst) {
+			t.Fatalf("v1 -> v2 -> v1 is not lossless: %s", diff.ObjectReflectDiff(src, dst))
+		}
+	}
+}
+
+func TestRayRoundTripFromHub(t *testing.T) {
+	f := newFuzzer(2)
+	for i := 0; i < fuzzIterations; i++ {
+		src := &rayv2.Ray{}
+		f.Fuzz(src)
+		spoke := &Ray{}
+		if err := spoke.ConvertFrom(src.DeepCopy()); err != nil {
+			t.Fatalf("failed to convert from v2: %v", err)
+		}
+		dst := &rayv2.Ray{}
+		if err := spoke.ConvertTo(dst); err != nil {
+			t.Fatalf("failed to convert to v2: %v", err)
+		}
+		dst.TypeMeta = src.TypeMeta
+		if !equality.Semantic.DeepEqual(src, dst) {
+			t.Fatalf("v2 -> v1 -> v2 is not lossless: %s", diff.ObjectReflectDiff(src, dst))
+		}
+	}
+}
+
+func TestRayConvertWorker(t *testing.T) {
+	replicas := int32(2)
+	src := &Ray{
+		Spec: RaySpec{
+			Worker:      ReplicaSpec{Replicas: &replicas},
+			Autoscaling: &AutoscalingSpec{MaxReplicas: 5},
+		},
+	}
+	hub := &rayv2.Ray{}
+	if err := src.ConvertTo(hub); err != nil {
+		t.Fatalf("failed to convert to v2: %v", err)
+	}
+	if len(hub.Spec.WorkerGroups) != 1 || hub.Spec.WorkerGroups[0].Name != DefaultWorkerGroupName {
+		t.Fatalf("expected the only worker group named default, got %v", hub.Spec.WorkerGroups)
+	}
+	group := hub.Spec.WorkerGroups[0]
+	if group.Autoscaling == nil || group.Autoscaling.MaxReplicas != 5 {
+		t.Errorf("expected spec.workerGroups[0].autoscaling.maxReplicas 5, got %v", group.Autoscaling)
+	}
+	if group.Replicas == nil || *group.Replicas != replicas {
+		t.Errorf("expected spec.workerGroups[0].replicas %d, got %v", replicas, group.Replicas)
+	}
+}
+
+func TestRayConvertHeadService(t *testing.T) {
+	src := &Ray{
+		Spec: RaySpec{
+			Head: &HeadSpec{
+				ServiceType:        corev1.ServiceTypeLoadBalancer,
+				ServiceAnnotations: map[string]string{"lb": "internal"},
+			},
+		},
+	}
+	hub := &rayv2.Ray{}
+	if err := src.ConvertTo(hub); err != nil {
+		t.Fatalf("failed to convert to v2: %v", err)
+	}
+	service := hub.Spec.Head.Service
+	if service.Type != corev1.ServiceTypeLoadBalancer || service.Annotations["lb"] != "internal" {
+		t.Errorf("expected spec.head.service to be converted, got %v", service)
+	}
+}
+
+func TestRayConvertWorkerGroupAutoscaling(t *testing.T) {
+	src := &rayv2.Ray{
+		Spec: rayv2.RaySpec{
+			WorkerGroups: []rayv2.WorkerGroupSpec{
+				{Name: "cpu", Autoscaling: &rayv2.AutoscalingSpec{MaxReplicas: 5}},
+				{Name: "gpu"},
+			},
+		},
+	}
+	spoke := &Ray{}
+	if err := spoke.ConvertFrom(src.DeepCopy()); err != nil {
+		t.Fatalf("failed to convert from v2: %v", err)
+	}
+	if len(spoke.Spec.WorkerGroups) != 2 || spoke.Spec.Autoscaling != nil {
+		t.Fatalf("expected the worker groups without autoscaling, got %v and %v",
+			spoke.Spec.WorkerGroups, spoke.Spec.Autoscaling)
+	}
+	if _, ok := spoke.Annotations[AnnotationWorkerGroupAutoscaling]; !ok {
+		t.Fatalf("expected the autoscaling to be kept in the annotation, got %v", spoke.Annotations)
+	}
+	if src.Annotations != nil {
+		t.Errorf("expected the annotations of the source not to be changed, got %v", src.Annotations)
+	}
+
+	// The group is removed in v1, thus its autoscaling is dropped.
+	spoke.Spec.WorkerGroups = spoke.Spec.WorkerGroups[1:]
+	dst := &rayv2.Ray{}
+	if err := spoke.ConvertTo(dst); err != nil {
+		t.Fatalf("failed to convert to v2: %v", err)
+	}
+	if len(dst.Spec.WorkerGroups) != 1 || dst.Spec.WorkerGroups[0].Autoscaling != nil {
+		t.Errorf("expected the group gpu without autoscaling, got %v", dst.Spec.WorkerGroups)
+	}
+	if len(dst.Annotations) != 0 {
+		t.Errorf("expected the annotation to be removed, got %v", dst.Annotations)
+	}
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"fmt"
+
 	"github.com/kubeflow/ray-operator/pkg/consts"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
@@ -10,6 +12,9 @@ import (
 
 const (
 	defaultImage = "rayproject/examples"
+	// defaultImageRepository is the repository of the default image when the Ray
+	// version is specified.
+	defaultImageRepository = "rayproject/ray"
 )
 
 var (
@@ -18,29 +23,6 @@ var (
 		"-c",
 		"--",
 	}
-	defaultHeadArgs  = []string{"ray start --head --redis-port=6379 --redis-shard-ports=6380,6381 --object-manager-port=12345 --node-manager-port=12346 --node-ip-address=$RAY_NODE_IP --block"}
-	defaultHeadPorts = []corev1.ContainerPort{
-		corev1.ContainerPort{
-			Name:          "redis-primary",
-			ContainerPort: 6379,
-		},
-		corev1.ContainerPort{
-			Name:          "redis-shard-0",
-			ContainerPort: 6380,
-		},
-		corev1.ContainerPort{
-			Name:          "redis-shard-1",
-			ContainerPort: 6381,
-		},
-		corev1.ContainerPort{
-			Name:          "object-manager",
-			ContainerPort: 12345,
-		},
-		corev1.ContainerPort{
-			Name:          "node-manager",
-			ContainerPort: 12346,
-		},
-	}
 
 	_   webhook.Defaulter = &Ray{}
 	log                   = ctrl.Log.WithName("ray-defaulter")
@@ -50,58 +32,109 @@ var (
 func (r *Ray) Default() {
 	log.V(1).Info("default", "name", r.Name)
 	if r.Spec.Head == nil {
-		r.Spec.Head = &ReplicaSpec{}
+		r.Spec.Head = &HeadSpec{}
+	}
+	image := defaultImage
+	if r.Spec.RayVersion != "" {
+		image = fmt.Sprintf("%s:%s", defaultImageRepository, r.Spec.RayVersion)
+	}
+	defaultHead(r.Spec.Head, image)
+	if len(r.Spec.WorkerGroups) == 0 {
+		defaultWorker(&r.Spec.Worker, image)
+	}
+	for i := range r.Spec.WorkerGroups {
+		defaultWorker(&r.Spec.WorkerGroups[i].ReplicaSpec, image)
 	}
-	defaultHead(r.Spec.Head)
 }
 
-func defaultHead(head *ReplicaSpec) {
+func defaultHead(head *HeadSpec, image string) {
+	if head.WorkloadKind == "" {
+		head.WorkloadKind = HeadWorkloadDeployment
+	}
 	if head.Replicas == nil {
 		head.Replicas = int32Ptr(1)
 	}
 	if head.Template == nil {
 		head.Template = &corev1.PodTemplateSpec{}
 	}
-	defaultHeadTemplate(head.Template)
+	defaultHeadTemplate(head.Template, image)
 }
 
-func defaultHeadTemplate(template *corev1.PodTemplateSpec) {
+func defaultHeadTemplate(template *corev1.PodTemplateSpec, image string) {
 	var c *v1.Container
-	if !hasHeadContainer(template) {
+	if !hasContainer(template, consts.ContainerRayHead) {
 		c = &v1.Container{
 			Name: consts.ContainerRayHead,
 		}
-		defaultHeadContainer(c)
+		defaultHeadContainer(c, image)
 		template.Spec.Containers = append(template.Spec.Containers, *c)
 		return
 	}
 	for i := range template.Spec.Containers {
 		if template.Spec.Containers[i].Name == consts.ContainerRayHead {
 			c = &template.Spec.Containers[i]
-			defaultHeadContainer(c)
+			defaultHeadContainer(c, image)
 			return
 		}
 	}
 }
 
-func defaultHeadContainer(c *corev1.Container) {
+// defaultHeadContainer sets the image and the command of the Ray container. The
+// `ray start` command line and the ports are set by the composer from spec.rayVersion
+// and spec.rayStartParams if they are not set.
+func defaultHeadContainer(c *corev1.Container, image string) {
 	if c.Image == "" {
-		c.Image = defaultImage
+		c.Image = image
 	}
 	if len(c.Command) == 0 {
 		c.Command = defaultCmd
 	}
-	if len(c.Args) == 0 {
-		c.Args = defaultHeadArgs
+}
+
+func defaultWorker(worker *ReplicaSpec, image string) {
+	if worker.Replicas == nil {
+		worker.Replicas = int32Ptr(1)
+	}
+	if worker.Template == nil {
+		worker.Template = &corev1.PodTemplateSpec{}
+	}
+	defaultWorkerTemplate(worker.Template, image)
+}
+
+func defaultWorkerTemplate(template *corev1.PodTemplateSpec, image string) {
+	var c *v1.Container
+	if !hasContainer(template, consts.ContainerRayWorker) {
+		c = &v1.Container{
+			Name: consts.ContainerRayWorker,
+		}
+		defaultWorkerContainer(c, image)
+		template.Spec.Containers = append(template.Spec.Containers, *c)
+		return
+	}
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == consts.ContainerRayWorker {
+			c = &template.Spec.Containers[i]
+			defaultWorkerContainer(c, image)
+			return
+		}
+	}
+}
+
+// defaultWorkerContainer sets the image and the command of the Ray container. The
+// `ray start` command line and the ports are set by the composer from spec.rayVersion
+// and spec.rayStartParams if they are not set.
+func defaultWorkerContainer(c *corev1.Container, image string) {
+	if c.Image == "" {
+		c.Image = image
 	}
-	if len(c.Ports) == 0 {
-		c.Ports = defaultHeadPorts
+	if len(c.Command) == 0 {
+		c.Command = defaultCmd
 	}
 }
 
-func hasHeadContainer(template *corev1.PodTemplateSpec) bool {
+func hasContainer(template *corev1.PodTemplateSpec, name string) bool {
 	for _, c := range template.Spec.Containers {
-		if c.Name == consts.ContainerRayHead {
+		if c.Name == name {
 			return true
 		}
 	}
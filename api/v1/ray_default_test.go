@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The Kubeflow community.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+func findContainer(template *corev1.PodTemplateSpec, name string) *corev1.Container {
+	if template == nil {
+		return nil
+	}
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == name {
+			return &template.Spec.Containers[i]
+		}
+	}
+	return nil
+}
+
+func TestDefaultEmptyRay(t *testing.T) {
+	ray := &Ray{}
+	ray.Default()
+
+	head := ray.Spec.Head
+	if head == nil {
+		t.Fatal("expected spec.head to be set")
+	}
+	if head.WorkloadKind != HeadWorkloadDeployment {
+		t.Errorf("expected workload kind %s, got %s", HeadWorkloadDeployment, head.WorkloadKind)
+	}
+	if head.Replicas == nil || *head.Replicas != 1 {
+		t.Errorf("expected 1 head replica, got %v", head.Replicas)
+	}
+	for _, c := range []struct {
+		template *corev1.PodTemplateSpec
+		name     string
+	}{
+		{head.Template, consts.ContainerRayHead},
+		{ray.Spec.Worker.Template, consts.ContainerRayWorker},
+	} {
+		container := findContainer(c.template, c.name)
+		if container == nil {
+			t.Fatalf("expected the container %s to be added", c.name)
+		}
+		if container.Image != defaultImage {
+			t.Errorf("expected the image %s of %s, got %s", defaultImage, c.name, container.Image)
+		}
+		if !equality.Semantic.DeepEqual(container.Command, defaultCmd) {
+			t.Errorf("expected the command %v of %s, got %v", defaultCmd, c.name, container.Command)
+		}
+		// The args and the ports are built by the composer.
+		if len(container.Args) != 0 || len(container.Ports) != 0 {
+			t.Errorf("expected no args and ports of %s, got %v and %v", c.name, container.Args, container.Ports)
+		}
+	}
+	if ray.Spec.Worker.Replicas == nil || *ray.Spec.Worker.Replicas != 1 {
+		t.Errorf("expected 1 worker replica, got %v", ray.Spec.Worker.Replicas)
+	}
+}
+
+func TestDefaultRayVersionImage(t *testing.T) {
+	ray := &Ray{Spec: RaySpec{RayVersion: "2.9.0"}}
+	ray.Default()
+	container := findContainer(ray.Spec.Head.Template, consts.ContainerRayHead)
+	if container == nil || container.Image != "rayproject/ray:2.9.0" {
+		t.Errorf("expected the image rayproject/ray:2.9.0, got %v", container)
+	}
+}
+
+func TestDefaultKeepsSpecifiedFields(t *testing.T) {
+	replicas := int32(3)
+	ray := &Ray{
+		Spec: RaySpec{
+			Head: &HeadSpec{
+				WorkloadKind: HeadWorkloadPod,
+				ReplicaSpec: ReplicaSpec{
+					Template: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{Name: "sidecar", Image: "busybox"},
+								{Name: consts.ContainerRayHead, Image: "custom", Args: []string{"ray start --head"}},
+							},
+						},
+					},
+				},
+			},
+			WorkerGroups: []WorkerGroupSpec{
+				{Name: "cpu", ReplicaSpec: ReplicaSpec{Replicas: &replicas}},
+			},
+		},
+	}
+	ray.Default()
+
+	if ray.Spec.Head.WorkloadKind != HeadWorkloadPod {
+		t.Errorf("expected workload kind %s, got %s", HeadWorkloadPod, ray.Spec.Head.WorkloadKind)
+	}
+	containers := ray.Spec.Head.Template.Spec.Containers
+	if len(containers) != 2 || containers[1].Image != "custom" || containers[1].Args[0] != "ray start --head" {
+		t.Errorf("expected the head containers to be kept, got %v", containers)
+	}
+	if !equality.Semantic.DeepEqual(containers[1].Command, defaultCmd) {
+		t.Errorf("expected the default command, got %v", containers[1].Command)
+	}
+	if len(containers[0].Command) != 0 {
+		t.Errorf("expected the sidecar not to be defaulted, got %v", containers[0].Command)
+	}
+	group := ray.Spec.WorkerGroups[0]
+	if *group.Replicas != 3 || findContainer(group.Template, consts.ContainerRayWorker) == nil {
+		t.Errorf("expected the worker group to be defaulted with 3 replicas, got %v", group)
+	}
+	// spec.worker is not defaulted together with the worker groups.
+	if ray.Spec.Worker.Template != nil {
+		t.Errorf("expected spec.worker not to be defaulted, got %v", ray.Spec.Worker)
+	}
+}
+
+func TestDefaultIsIdempotent(t *testing.T) {
+	ray := &Ray{}
+	ray.Default()
+	defaulted := ray.DeepCopy()
+	ray.Default()
+	if !equality.Semantic.DeepEqual(ray, defaulted) {
+		t.Errorf("expected Default to be idempotent, got %v and %v", defaulted.Spec, ray.Spec)
+	}
+}
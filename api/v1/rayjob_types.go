@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Kubeflow community.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RayJobSpec defines the desired state of RayJob
+type RayJobSpec struct {
+	// RaySpec is the specification of the Ray cluster which runs the job.
+	RaySpec RaySpec `json:"rayClusterSpec"`
+	// Entrypoint is the command to run in the Ray cluster, e.g. "python my_script.py".
+	Entrypoint string `json:"entrypoint"`
+	// RuntimeEnv is the runtime environment of the job in JSON format.
+	// +optional
+	RuntimeEnv string `json:"runtimeEnv,omitempty"`
+	// ShutdownAfterJobFinishes deletes the Ray cluster after the job finishes.
+	// +optional
+	ShutdownAfterJobFinishes bool `json:"shutdownAfterJobFinishes,omitempty"`
+	// TTLSecondsAfterFinished is the duration the Ray cluster is kept after the
+	// job finishes if ShutdownAfterJobFinishes is true. Defaults to 0.
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// RayJobPhase is the phase of the RayJob.
+type RayJobPhase string
+
+const (
+	RayJobPending   RayJobPhase = "Pending"
+	RayJobRunning   RayJobPhase = "Running"
+	RayJobSucceeded RayJobPhase = "Succeeded"
+	RayJobFailed    RayJobPhase = "Failed"
+)
+
+// RayJobStatus defines the observed state of RayJob
+type RayJobStatus struct {
+	// JobStatus is the phase of the job, one of Pending, Running, Succeeded and Failed.
+	JobStatus RayJobPhase `json:"jobStatus,omitempty"`
+	// A human readable message indicating details about the job.
+	Message string `json:"message,omitempty"`
+	// RayName is the name of the Ray cluster which runs the job.
+	RayName string `json:"rayName,omitempty"`
+	// RayDeleted shows if the Ray cluster is deleted after the job finishes.
+	RayDeleted bool `json:"rayDeleted,omitempty"`
+
+	// Represents time when the job was acknowledged by the ray operator.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Represents time when the job finished.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// The generation observed by the ray operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// IsFinished returns true if the job succeeded or failed.
+func (s *RayJobStatus) IsFinished() bool {
+	return s.JobStatus == RayJobSucceeded || s.JobStatus == RayJobFailed
+}
+
+// +kubebuilder:object:root=true
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.jobStatus"
+// +kubebuilder:printcolumn:name="Ray",type="string",JSONPath=".status.rayName"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RayJob is the Schema for the rayjobs API
+type RayJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RayJobSpec   `json:"spec,omitempty"`
+	Status RayJobStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RayJobList contains a list of RayJob
+type RayJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RayJob `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RayJob{}, &RayJobList{})
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubeflow community.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RayServiceSpec defines the desired state of RayService
+type RayServiceSpec struct {
+	// RaySpec is the specification of the Ray cluster which serves the deployments.
+	// A new cluster is created when it is changed, and the traffic is switched to
+	// the new cluster after it is healthy.
+	RaySpec RaySpec `json:"rayClusterSpec"`
+	// ServeConfig is the Ray Serve config in YAML or JSON format. It is applied
+	// to the cluster by the Ray dashboard.
+	ServeConfig string `json:"serveConfig"`
+}
+
+// RayServiceClusterStatus is the status of a Ray cluster owned by the RayService.
+type RayServiceClusterStatus struct {
+	// RayName is the name of the Ray cluster.
+	RayName string `json:"rayName"`
+	// ServeConfigHash is the hash of the serve config applied to the cluster.
+	// +optional
+	ServeConfigHash string `json:"serveConfigHash,omitempty"`
+	// Applications maps the name of the serve application to its status.
+	// +optional
+	Applications map[string]string `json:"applications,omitempty"`
+	// ServeHealthy shows if all serve applications are running.
+	// +optional
+	ServeHealthy bool `json:"serveHealthy,omitempty"`
+}
+
+// RayServiceStatus defines the observed state of RayService
+type RayServiceStatus struct {
+	// ActiveCluster is the cluster which serves the traffic.
+	// +optional
+	ActiveCluster *RayServiceClusterStatus `json:"activeCluster,omitempty"`
+	// PendingCluster is the cluster which is being prepared to replace the active cluster.
+	// +optional
+	PendingCluster *RayServiceClusterStatus `json:"pendingCluster,omitempty"`
+	// ServiceName is the name of the stable service which selects the active cluster.
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+	// A human readable message indicating details about the service.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Represents last time when the RayService was reconciled.
+	// +optional
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+	// The generation observed by the ray operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Active",type="string",JSONPath=".status.activeCluster.rayName"
+// +kubebuilder:printcolumn:name="Pending",type="string",JSONPath=".status.pendingCluster.rayName"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// RayService is the Schema for the rayservices API
+type RayService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RayServiceSpec   `json:"spec,omitempty"`
+	Status RayServiceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RayServiceList contains a list of RayService
+type RayServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RayService `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RayService{}, &RayServiceList{})
+}
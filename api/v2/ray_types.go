@@ -0,0 +1,526 @@
+/*
+Copyright 2019 The Kubeflow community.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RaySpec defines the desired state of Ray
+type RaySpec struct {
+	// RayVersion is the version of Ray run by the cluster, e.g. 2.9.0. It selects the
+	// default image and the port profile of `ray start`. Ray 1.11 and later run the GCS
+	// without Redis, while the earlier versions and an empty version use the Redis shards.
+	// +kubebuilder:validation:Pattern=`^[0-9]+\.[0-9]+(\.[0-9]+)?$`
+	// +optional
+	RayVersion string `json:"rayVersion,omitempty"`
+	// RayStartParams are the parameters of `ray start` without the leading dashes, e.g.
+	// {"num-cpus": "2"}. An empty value is passed as a flag without the value. They
+	// override the ports of the port profile, and the parameters which only apply to
+	// the head, e.g. dashboard-port, are not passed to the workers. They are used only
+	// if the args of the Ray container are not set.
+	// +optional
+	RayStartParams map[string]string `json:"rayStartParams,omitempty"`
+
+	Head *HeadSpec `json:"head,omitempty"`
+	// WorkerGroups are groups of workers, each of them has its own pod template. The
+	// workers described by spec.worker of v1 are the only group named default.
+	// +optional
+	WorkerGroups []WorkerGroupSpec `json:"workerGroups,omitempty"`
+	// Termination enables the graceful termination of the Ray by a finalizer. The workers
+	// are drained and the pre-delete hook is run before the head is deleted.
+	// +optional
+	Termination *TerminationSpec `json:"termination,omitempty"`
+	// Scheduling enables the gang scheduling of the Ray by a PodGroup, so that the head
+	// and the minimum workers are scheduled all together or not at all.
+	// +optional
+	Scheduling *SchedulingSpec `json:"scheduling,omitempty"`
+	// HeadFaultTolerance persists the metadata of the GCS to an external Redis, so that the
+	// cluster survives the restarts of the head. It requires Ray 2.0 or later.
+	// +optional
+	HeadFaultTolerance *HeadFaultToleranceSpec `json:"headFaultTolerance,omitempty"`
+}
+
+// HeadSpec is the specification for Head.
+type HeadSpec struct {
+	ReplicaSpec `json:",inline"`
+
+	// WorkloadKind is the kind of the workload which runs the head, one of Deployment,
+	// Pod and StatefulSet. Defaults to Deployment.
+	// +optional
+	WorkloadKind HeadWorkloadKind `json:"workloadKind,omitempty"`
+
+	// Service configures the head service named {ray.name}-head.
+	// +optional
+	Service HeadServiceSpec `json:"service,omitempty"`
+	// Ingress exposes the dashboard and the Ray client server of the head by an Ingress
+	// named {ray.name}-head.
+	// +optional
+	Ingress *HeadIngressSpec `json:"ingress,omitempty"`
+}
+
+// HeadServiceSpec is the specification for the service of the head.
+type HeadServiceSpec struct {
+	// Type is the type of the head service, one of ClusterIP, NodePort and
+	// LoadBalancer. Defaults to ClusterIP.
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Annotations are added to the head service, e.g. to configure the load balancer.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// HeadIngressSpec is the specification for the Ingress of the head.
+type HeadIngressSpec struct {
+	// DashboardHost is the host which is routed to the dashboard port of the head.
+	// +optional
+	DashboardHost string `json:"dashboardHost,omitempty"`
+	// ClientHost is the host which is routed to the Ray client server port of the head.
+	// The Ray client uses gRPC, which must be supported by the ingress controller.
+	// +optional
+	ClientHost string `json:"clientHost,omitempty"`
+	// Annotations are added to the Ingress, e.g. to select the ingress class.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// TLSSecretName is the name of the Secret which terminates TLS for the hosts.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// HeadWorkloadKind is the kind of the workload which runs the head.
+type HeadWorkloadKind string
+
+const (
+	HeadWorkloadDeployment  HeadWorkloadKind = "Deployment"
+	HeadWorkloadPod         HeadWorkloadKind = "Pod"
+	HeadWorkloadStatefulSet HeadWorkloadKind = "StatefulSet"
+)
+
+// WorkerGroupSpec is the specification for a group of workers.
+type WorkerGroupSpec struct {
+	// Name of the group, it must be unique in the Ray.
+	Name string `json:"name"`
+
+	ReplicaSpec `json:",inline"`
+
+	// RayResources are the custom resources which every worker in the group
+	// advertises to Ray, e.g. {"high-memory": 1}.
+	// +optional
+	RayResources map[string]int64 `json:"rayResources,omitempty"`
+	// Autoscaling scales the workers in the group according to the pending resource
+	// demand of the cluster. It is only supported for the only group named default,
+	// which has no RayResources.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+}
+
+// AutoscalingSpec is the specification for the autoscaling of the workers.
+type AutoscalingSpec struct {
+	// MinReplicas is the lower limit for the number of workers. Defaults to 1.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the upper limit for the number of workers.
+	MaxReplicas int32 `json:"maxReplicas"`
+	// UpscalingSpeed is the percentage of the current workers which could be
+	// added in one scale up, at least one worker is added. Defaults to 100.
+	// +optional
+	UpscalingSpeed *int32 `json:"upscalingSpeed,omitempty"`
+	// IdleTimeoutSeconds is the duration the workers must be idle before
+	// they are removed. Defaults to 300.
+	// +optional
+	IdleTimeoutSeconds *int32 `json:"idleTimeoutSeconds,omitempty"`
+}
+
+// TerminationSpec is the specification for the graceful termination of the Ray.
+type TerminationSpec struct {
+	// GracePeriodSeconds is the duration to wait for the workers to drain after
+	// they are scaled to zero. Defaults to 60.
+	// +optional
+	GracePeriodSeconds *int32 `json:"gracePeriodSeconds,omitempty"`
+	// PreDeleteHook is the Job which runs after the workers are drained and before
+	// the head is deleted.
+	// +optional
+	PreDeleteHook *batchv1.JobSpec `json:"preDeleteHook,omitempty"`
+}
+
+// SchedulingSpec is the specification for the gang scheduling of the Ray.
+type SchedulingSpec struct {
+	// Provider is the gang scheduler which the PodGroup is created for, one of Volcano
+	// and Coscheduling, the coscheduling plugin of scheduler-plugins.
+	// +kubebuilder:validation:Enum=Volcano;Coscheduling
+	Provider SchedulingProvider `json:"provider"`
+	// SchedulerName is the scheduler of the pods. Defaults to volcano for Volcano and
+	// scheduler-plugins-scheduler for Coscheduling.
+	// +optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+	// MinWorkers is the number of the workers which must be scheduled together with the
+	// head. Defaults to the minimum replicas of the autoscaling if it is enabled, or the
+	// replicas of all the worker groups otherwise.
+	// +optional
+	MinWorkers *int32 `json:"minWorkers,omitempty"`
+	// Queue is the Volcano queue of the PodGroup. It is ignored by Coscheduling.
+	// +optional
+	Queue string `json:"queue,omitempty"`
+	// ScheduleTimeoutSeconds is the duration to wait for all the members of the PodGroup
+	// to be scheduled. It is ignored by Volcano.
+	// +optional
+	ScheduleTimeoutSeconds *int32 `json:"scheduleTimeoutSeconds,omitempty"`
+}
+
+// SchedulingProvider is the gang scheduler of the Ray.
+type SchedulingProvider string
+
+const (
+	// SchedulingVolcano schedules the pods by Volcano with a scheduling.volcano.sh PodGroup.
+	SchedulingVolcano SchedulingProvider = "Volcano"
+	// SchedulingCoscheduling schedules the pods by the coscheduling plugin of
+	// scheduler-plugins with a scheduling.x-k8s.io PodGroup.
+	SchedulingCoscheduling SchedulingProvider = "Coscheduling"
+)
+
+// HeadFaultToleranceSpec is the specification for the GCS fault tolerance of the head.
+type HeadFaultToleranceSpec struct {
+	// RedisAddress is the address of the external Redis in the form of host:port.
+	RedisAddress string `json:"redisAddress"`
+	// RedisPasswordSecret selects the key of the Secret in the namespace of the Ray which
+	// contains the password of the Redis.
+	// +optional
+	RedisPasswordSecret *corev1.SecretKeySelector `json:"redisPasswordSecret,omitempty"`
+	// ExternalStorageNamespace isolates the metadata of the Ray from the other Rays which
+	// share the Redis. Defaults to the UID of the Ray.
+	// +optional
+	ExternalStorageNamespace string `json:"externalStorageNamespace,omitempty"`
+	// WorkerReconnectTimeoutSeconds is the duration the workers wait for a restarted head
+	// before they exit. Defaults to 600.
+	// +optional
+	WorkerReconnectTimeoutSeconds *int32 `json:"workerReconnectTimeoutSeconds,omitempty"`
+}
+
+// ReplicaSpec is the replica specification for Head and Worker.
+type ReplicaSpec struct {
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Describes the pod that will be created for this replica.
+	Template *corev1.PodTemplateSpec `json:"template,omitempty"`
+
+	// Probes configures the readiness and liveness probes which are added to the Ray
+	// container if it does not define them.
+	// +optional
+	Probes *ProbeSpec `json:"probes,omitempty"`
+}
+
+// ProbeSpec is the timing of the default readiness and liveness probes. The probes
+// check the GCS/Redis port of the head and the node manager port of the workers, and
+// are added only if the Ray container exposes the port.
+type ProbeSpec struct {
+	// Number of seconds after the container has started before the readiness probe is
+	// initiated. Defaults to 10 seconds.
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// Number of seconds after which the probes time out. Defaults to 5 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// How often in seconds to perform the probes. Defaults to 10 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// Number of consecutive failures after which the container is not ready. Defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// Number of seconds after the container has started before the liveness probe is
+	// initiated. It is larger than the one of the readiness probe, so that a slow start
+	// of Ray does not restart the container. Defaults to 30 seconds.
+	// +optional
+	LivenessInitialDelaySeconds *int32 `json:"livenessInitialDelaySeconds,omitempty"`
+	// Number of consecutive failures after which the container is restarted by the
+	// liveness probe. Defaults to 6.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	LivenessFailureThreshold *int32 `json:"livenessFailureThreshold,omitempty"`
+}
+
+// RayPhase is the phase of the Ray.
+type RayPhase string
+
+const (
+	// RayPending means the Ray has never been healthy and its components are starting.
+	RayPending RayPhase = "Pending"
+	// RayRunning means all components of the Ray are available.
+	RayRunning RayPhase = "Running"
+	// RayDegraded means some components of the Ray are not available, while the head
+	// is available or the Ray has been running.
+	RayDegraded RayPhase = "Degraded"
+	// RayFailed means the Ray cannot be reconciled, or the head failed before the Ray
+	// is running.
+	RayFailed RayPhase = "Failed"
+	// RayTerminating means the Ray is being deleted gracefully.
+	RayTerminating RayPhase = "Terminating"
+)
+
+// RayStatus defines the observed state of Ray
+type RayStatus struct {
+	// Phase is the phase of the Ray, one of Pending, Running, Degraded, Failed and Terminating.
+	// +optional
+	Phase RayPhase `json:"phase,omitempty"`
+	// A human readable message indicating details about the phase.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// HeadServiceIP is the cluster IP of the head service.
+	// +optional
+	HeadServiceIP string `json:"headServiceIP,omitempty"`
+	// DashboardURL is the URL of the Ray dashboard through the head service.
+	// +optional
+	DashboardURL string `json:"dashboardURL,omitempty"`
+	// Endpoints are the addresses which the ports of the head are exposed at.
+	// +optional
+	Endpoints []EndpointStatus `json:"endpoints,omitempty"`
+
+	Head ReplicaStatus `json:"head,omitempty"`
+	// WorkerGroups is the status of every worker group.
+	WorkerGroups []WorkerGroupStatus `json:"workerGroups,omitempty"`
+	// WorkerReplicas is the number of the workers in the group named default, it is
+	// the status replicas of the scale subresource of v1.
+	// +optional
+	WorkerReplicas int32 `json:"workerReplicas,omitempty"`
+	// WorkerSelector is the label selector of the workers in the group named default,
+	// it is the label selector of the scale subresource of v1.
+	// +optional
+	WorkerSelector string `json:"workerSelector,omitempty"`
+	// Autoscaler is the status of the autoscaler, it is set only if the autoscaling is enabled.
+	// +optional
+	Autoscaler *AutoscalerStatus `json:"autoscaler,omitempty"`
+	// ClusterResources is the nodes and the resources registered with the head, it is
+	// set once the head can be queried.
+	// +optional
+	ClusterResources *ClusterResourcesStatus `json:"clusterResources,omitempty"`
+	// Scheduling is the status of the PodGroup, it is set only if the gang scheduling
+	// is enabled.
+	// +optional
+	Scheduling *SchedulingStatus `json:"scheduling,omitempty"`
+	// Conditions is an array of current observed ray conditions.
+	Conditions []RayCondition `json:"conditions,omitempty"`
+
+	// Represents time when the ray was acknowledged by the ray operator.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// The generation observed by the ray operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Represents last time when the Ray was reconciled.
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+}
+
+// ReplicaStatus is the status field for the replica.
+type ReplicaStatus struct {
+	// Total number of non-terminated pods targeted by this replica (their labels match the selector).
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Total number of non-terminated pods targeted by this replica that have the desired template spec.
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// Total number of ready pods targeted by this replica.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Total number of available pods (ready for at least minReadySeconds) targeted by this replica.
+	// +optional
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+
+	// Total number of unavailable pods targeted by this replica. This is the total number of
+	// pods that are still required for the replica to have 100% available capacity. They may
+	// either be pods that are running but not yet available or pods that still have not been created.
+	// +optional
+	UnavailableReplicas int32 `json:"unavailableReplicas,omitempty"`
+}
+
+// AutoscalerStatus is the status field for the autoscaler.
+type AutoscalerStatus struct {
+	// DesiredReplicas is the number of workers decided by the autoscaler.
+	DesiredReplicas int32 `json:"desiredReplicas"`
+	// PendingResources is the resource demand which cannot be scheduled in the cluster.
+	// +optional
+	PendingResources map[string]string `json:"pendingResources,omitempty"`
+	// IdleSince is the time since when some workers are idle.
+	// +optional
+	IdleSince *metav1.Time `json:"idleSince,omitempty"`
+	// LastScaleTime is the last time the autoscaler changed the number of workers.
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+	// A human readable message indicating details about the last decision.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// EndpointType is how an endpoint of the head is exposed.
+type EndpointType string
+
+const (
+	// EndpointClusterIP is reached at the DNS name of the head service in the cluster.
+	EndpointClusterIP EndpointType = "ClusterIP"
+	// EndpointNodePort is reached at the node port on any node.
+	EndpointNodePort EndpointType = "NodePort"
+	// EndpointLoadBalancer is reached at the load balancer of the head service.
+	EndpointLoadBalancer EndpointType = "LoadBalancer"
+	// EndpointIngress is reached at the host of the Ingress of the head.
+	EndpointIngress EndpointType = "Ingress"
+)
+
+// EndpointStatus is the status field for an endpoint of the head.
+type EndpointStatus struct {
+	// Name is the name of the port of the head service.
+	Name string `json:"name"`
+	// Type is how the endpoint is exposed, one of ClusterIP, NodePort, LoadBalancer
+	// and Ingress.
+	Type EndpointType `json:"type"`
+	// Host is the host name or the IP of the endpoint. It is empty for a NodePort
+	// endpoint, which is reached at the IP of any node.
+	// +optional
+	Host string `json:"host,omitempty"`
+	// Port is the port of the endpoint.
+	Port int32 `json:"port"`
+}
+
+// ClusterResourcesStatus is the status field for the nodes registered with the head.
+type ClusterResourcesStatus struct {
+	// AliveNodes is the number of the alive nodes registered with the head, including
+	// the head itself.
+	AliveNodes int32 `json:"aliveNodes"`
+	// AliveWorkers is the number of the alive worker nodes registered with the head.
+	AliveWorkers int32 `json:"aliveWorkers"`
+	// Resources is the total cpu, memory and nvidia.com/gpu provided by the alive nodes.
+	// +optional
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+}
+
+// SchedulingStatus is the status field for the PodGroup of the gang scheduling.
+type SchedulingStatus struct {
+	// Provider is the gang scheduler which the PodGroup is created for.
+	Provider SchedulingProvider `json:"provider"`
+	// PodGroupName is the name of the PodGroup.
+	PodGroupName string `json:"podGroupName"`
+	// MinMember is the number of the pods which must be scheduled together.
+	MinMember int32 `json:"minMember"`
+	// Phase is the phase of the PodGroup reported by the scheduler, e.g. Pending,
+	// Inqueue or Running.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+}
+
+// WorkerGroupStatus is the status field for the worker group.
+type WorkerGroupStatus struct {
+	// Name of the group.
+	Name string `json:"name"`
+
+	ReplicaStatus `json:",inline"`
+
+	// Conditions is an array of current observed conditions of the group's deployment.
+	Conditions []RayCondition `json:"conditions,omitempty"`
+}
+
+// RayCondition is the status condition for the Ray.
+type RayCondition struct {
+	// Type of the condition.
+	Type RayConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+	// The last time this condition was updated.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// RayConditionType is the type for the RayCondition.
+type RayConditionType string
+
+const (
+	// RayHealth shows if the Ray is healthy.
+	RayHealth RayConditionType = "Health"
+
+	// RayHeadAvailable shows if the head is available when it runs in a Pod or a StatefulSet.
+	RayHeadAvailable RayConditionType = "RayHeadAvailable"
+	// RayWorkersRegistered shows if all ready worker pods are registered with the head
+	// as alive nodes.
+	RayWorkersRegistered RayConditionType = "WorkersRegistered"
+	// RayHeadReachable shows if the GCS/Redis port and the dashboard of the head can be
+	// reached through the head service.
+	RayHeadReachable RayConditionType = "RayHeadReachable"
+	// RayGCSFaultToleranceActive shows if the GCS persists the metadata to the external
+	// Redis, thus the workers are kept while the head restarts.
+	RayGCSFaultToleranceActive RayConditionType = "GCSFaultToleranceActive"
+	// RayAutoscalerReady shows if the autoscaler gets the resource demand from the head,
+	// it is set only if the autoscaling is enabled.
+	RayAutoscalerReady RayConditionType = "AutoscalerReady"
+
+	RayHeadDeploymentAvailable      RayConditionType = "RayHeadDeploymentAvailable"
+	RayHeadDeploymentProgressing    RayConditionType = "RayHeadDeploymentProgressing"
+	RayHeadDeploymentReplicaFailure RayConditionType = "RayHeadDeploymentReplicaFailure"
+
+	RayWorkerDeploymentAvailable      RayConditionType = "RayWorkerDeploymentAvailable"
+	RayWorkerDeploymentProgressing    RayConditionType = "RayWorkerDeploymentProgressing"
+	RayWorkerDeploymentReplicaFailure RayConditionType = "RayWorkerDeploymentReplicaFailure"
+
+	// RayValidationFailed shows if the Ray specification is rejected by the validator.
+	RayValidationFailed RayConditionType = "ValidationFailed"
+	// RayReconcileError shows if the Ray cannot be reconciled because of an error which
+	// cannot be fixed by retrying, e.g. an invalid specification.
+	RayReconcileError RayConditionType = "ReconcileError"
+)
+
+// +kubebuilder:object:root=true
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Head IP",type="string",JSONPath=".status.headServiceIP"
+// +kubebuilder:printcolumn:name="Dashboard",type="string",JSONPath=".status.dashboardURL"
+// +kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.message",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Ray is the Schema for the rays API. The version is not served until the conversion
+// webhook is a part of the default install, see config/crd/kustomization.yaml.
+type Ray struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RaySpec   `json:"spec,omitempty"`
+	Status RayStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RayList contains a list of Ray
+type RayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Ray `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Ray{}, &RayList{})
+}
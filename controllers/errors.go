@@ -0,0 +1,25 @@
+package controllers
+
+// terminalError is an error which cannot be fixed by retrying, e.g. an invalid
+// specification. It is recorded in the ReconcileError condition of the Ray and
+// the request is not requeued, while the other errors are requeued with backoff.
+type terminalError struct {
+	reason string
+	err    error
+}
+
+func newTerminalError(reason string, err error) *terminalError {
+	return &terminalError{
+		reason: reason,
+		err:    err,
+	}
+}
+
+func (e *terminalError) Error() string {
+	return e.err.Error()
+}
+
+func isTerminalError(err error) bool {
+	_, ok := err.(*terminalError)
+	return ok
+}
@@ -3,14 +3,22 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
 	"github.com/kubeflow/ray-operator/pkg/consts"
 )
 
@@ -19,11 +27,12 @@ func (r *RayReconciler) createOrUpdateService(ray *rayv1.Ray, service *corev1.Se
 	err := r.Get(context.TODO(), types.NamespacedName{Name: service.Name, Namespace: service.Namespace}, found)
 	if err != nil && errors.IsNotFound(err) {
 		r.Log.V(1).Info("Creating Service", "namespace", service.Namespace, "name", service.Name)
+		setManagedKeys(service, service)
 		err = r.Create(context.TODO(), service)
 		if err != nil {
 			r.Log.Error(err, "Failed to create the service")
 			r.Event(ray, consts.EventWarning, consts.ReasonCreate,
-				fmt.Sprintf("Failed to create the service %s", service.Name))
+				fmt.Sprintf("Failed to create the service %s: %v", service.Name, err))
 			return nil, err
 		}
 		r.Event(ray, consts.EventNormal, consts.ReasonCreate,
@@ -32,22 +41,25 @@ func (r *RayReconciler) createOrUpdateService(ray *rayv1.Ray, service *corev1.Se
 	} else if err != nil {
 		r.Log.Error(err, "Failed to get the service")
 		r.Event(ray, consts.EventWarning, consts.ReasonCreate,
-			fmt.Sprintf("Failed to create the service %s", service.Name))
+			fmt.Sprintf("Failed to create the service %s: %v", service.Name, err))
 		return nil, err
 	}
 
-	if doServiceChanged(service, found) {
-		r.Log.V(1).Info("Updating service", "namespace", service.Namespace, "name", service.Name)
-		err = r.Update(context.TODO(), service)
+	if changes := diffService(service, found); len(changes) != 0 {
+		r.Log.V(1).Info("Updating service", "namespace", service.Namespace, "name", service.Name,
+			"changes", changes)
+		updated := mergeService(service, found)
+		err = r.Update(context.TODO(), updated)
 		if err != nil {
 			r.Log.Error(err, "Failed to update the service")
 			r.Event(ray, consts.EventWarning, consts.ReasonUpdate,
-				fmt.Sprintf("Failed to update the service %s", service.Name))
+				fmt.Sprintf("Failed to update the service %s: %v", service.Name, err))
 			return nil, err
 		}
 		r.Event(ray, consts.EventNormal, consts.ReasonUpdate,
-			fmt.Sprintf("Successfully update the service %s", service.Name))
-		return service, nil
+			fmt.Sprintf("Successfully update the service %s, changed: %s",
+				service.Name, strings.Join(changes, ", ")))
+		return updated, nil
 	}
 	return found, nil
 }
@@ -59,11 +71,12 @@ func (r *RayReconciler) createOrUpdateDeployment(ray *rayv1.Ray,
 	err := r.Get(context.TODO(), types.NamespacedName{Name: deploy.Name, Namespace: deploy.Namespace}, found)
 	if err != nil && errors.IsNotFound(err) {
 		r.Log.V(1).Info("Creating Deployment", "namespace", deploy.Namespace, "name", deploy.Name)
+		setManagedKeys(deploy, deploy)
 		err = r.Create(context.TODO(), deploy)
 		if err != nil {
 			r.Log.Error(err, "Failed to create the deployment")
 			r.Event(ray, consts.EventWarning, consts.ReasonCreate,
-				fmt.Sprintf("Failed to create the deployment %s", deploy.Name))
+				fmt.Sprintf("Failed to create the deployment %s: %v", deploy.Name, err))
 			return nil, err
 		}
 		r.Event(ray, consts.EventNormal, consts.ReasonCreate,
@@ -72,51 +85,525 @@ func (r *RayReconciler) createOrUpdateDeployment(ray *rayv1.Ray,
 	} else if err != nil {
 		r.Log.Error(err, "Failed to get the deployment")
 		r.Event(ray, consts.EventWarning, consts.ReasonCreate,
-			fmt.Sprintf("Failed to create the deployment %s", deploy.Name))
+			fmt.Sprintf("Failed to create the deployment %s: %v", deploy.Name, err))
 		return nil, err
 	}
 
-	if doDeploymentChanged(deploy, found) {
-		r.Log.V(1).Info("Updating Deployment", "namespace", deploy.Namespace, "name", deploy.Name)
-		err = r.Update(context.TODO(), deploy)
+	if changes := diffDeployment(deploy, found); len(changes) != 0 {
+		r.Log.V(1).Info("Updating Deployment", "namespace", deploy.Namespace, "name", deploy.Name,
+			"changes", changes)
+		updated := mergeDeployment(deploy, found)
+		err = r.Update(context.TODO(), updated)
 		if err != nil {
 			r.Log.Error(err, "Failed to update the deployment")
 			r.Event(ray, consts.EventWarning, consts.ReasonUpdate,
-				fmt.Sprintf("Failed to update the deployment %s", deploy.Name))
+				fmt.Sprintf("Failed to update the deployment %s: %v", deploy.Name, err))
 			return nil, err
 		}
 		r.Event(ray, consts.EventNormal, consts.ReasonUpdate,
-			fmt.Sprintf("Successfully update the deployment %s", deploy.Name))
-		return deploy, nil
+			fmt.Sprintf("Successfully update the deployment %s, changed: %s",
+				deploy.Name, strings.Join(changes, ", ")))
+		return updated, nil
 	}
 	return found, nil
 }
 
-// doServiceChanged checks if a serivce should be updated.
-func doServiceChanged(new *corev1.Service, old *corev1.Service) bool {
-	if len(new.Spec.Ports) != len(old.Spec.Ports) {
-		return true
+// syncHead creates or updates the workload of the head according to the workload kind,
+// and deletes the head workloads of the other kinds. It returns the actual workload,
+// which is a Deployment, a StatefulSet or a Pod.
+func (r *RayReconciler) syncHead(ray *rayv1.Ray) (runtime.Object, error) {
+	kind := ray.Spec.GetHeadWorkloadKind()
+	if err := r.deleteStaleHeads(ray, kind); err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case rayv1.HeadWorkloadPod:
+		desired, err := r.Composer.DesiredHeadPod(ray)
+		if err != nil {
+			return nil, newTerminalError(consts.ReasonComposeFailed, err)
+		}
+		return r.createOrRecreatePod(ray, desired)
+	case rayv1.HeadWorkloadStatefulSet:
+		// The governing service must exist before the StatefulSet.
+		service, err := r.Composer.DesiredHeadGoverningService(ray)
+		if err != nil {
+			return nil, newTerminalError(consts.ReasonComposeFailed, err)
+		}
+		if _, err := r.createOrUpdateService(ray, service); err != nil {
+			return nil, err
+		}
+		desired, err := r.Composer.DesiredHeadStatefulSet(ray)
+		if err != nil {
+			return nil, newTerminalError(consts.ReasonComposeFailed, err)
+		}
+		return r.createOrUpdateStatefulSet(ray, desired)
+	default:
+		desired, err := r.Composer.DesiredHead(ray)
+		if err != nil {
+			return nil, newTerminalError(consts.ReasonComposeFailed, err)
+		}
+		return r.createOrUpdateDeployment(ray, desired)
+	}
+}
+
+// deleteStaleHeads deletes the head workloads owned by the Ray whose kind is not the
+// desired one, thus there is at most one head when the workload kind is changed. The
+// governing service of the StatefulSet is deleted together with it.
+func (r *RayReconciler) deleteStaleHeads(ray *rayv1.Ray, kind rayv1.HeadWorkloadKind) error {
+	type staleObject struct {
+		obj  runtime.Object
+		name string
+	}
+	headName := composer.GetHeadName(ray.Name)
+	stale := []staleObject{}
+	if kind != rayv1.HeadWorkloadDeployment {
+		stale = append(stale, staleObject{&appsv1.Deployment{}, headName})
+	}
+	if kind != rayv1.HeadWorkloadStatefulSet {
+		stale = append(stale, staleObject{&appsv1.StatefulSet{}, headName},
+			staleObject{&corev1.Service{}, composer.GetHeadGoverningServiceName(ray.Name)})
+	}
+	if kind != rayv1.HeadWorkloadPod {
+		stale = append(stale, staleObject{&corev1.Pod{}, headName})
+	}
+
+	for _, so := range stale {
+		obj, name := so.obj, so.name
+		err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: ray.Namespace}, obj)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			r.Log.Error(err, "Failed to get the head")
+			r.Event(ray, consts.EventWarning, consts.ReasonDelete,
+				fmt.Sprintf("Failed to get the head %s: %v", name, err))
+			return err
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(accessor, ray) || accessor.GetDeletionTimestamp() != nil {
+			continue
+		}
+		kind := reflect.TypeOf(obj).Elem().Name()
+		r.Log.V(1).Info("Deleting "+kind, "namespace", ray.Namespace, "name", name)
+		if err := r.Delete(context.TODO(), obj); err != nil && !errors.IsNotFound(err) {
+			r.Log.Error(err, "Failed to delete the head")
+			r.Event(ray, consts.EventWarning, consts.ReasonDelete,
+				fmt.Sprintf("Failed to delete the %s %s: %v", strings.ToLower(kind), name, err))
+			return err
+		}
+		r.Event(ray, consts.EventNormal, consts.ReasonDelete,
+			fmt.Sprintf("Successfully delete the %s %s", strings.ToLower(kind), name))
+	}
+	return nil
+}
+
+func (r *RayReconciler) createOrUpdateStatefulSet(ray *rayv1.Ray,
+	sts *appsv1.StatefulSet) (*appsv1.StatefulSet, error) {
+	found := &appsv1.StatefulSet{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: sts.Name, Namespace: sts.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		r.Log.V(1).Info("Creating StatefulSet", "namespace", sts.Namespace, "name", sts.Name)
+		setManagedKeys(sts, sts)
+		err = r.Create(context.TODO(), sts)
+		if err != nil {
+			r.Log.Error(err, "Failed to create the statefulset")
+			r.Event(ray, consts.EventWarning, consts.ReasonCreate,
+				fmt.Sprintf("Failed to create the statefulset %s: %v", sts.Name, err))
+			return nil, err
+		}
+		r.Event(ray, consts.EventNormal, consts.ReasonCreate,
+			fmt.Sprintf("Successfully create the statefulset %s", sts.Name))
+		return sts, nil
+	} else if err != nil {
+		r.Log.Error(err, "Failed to get the statefulset")
+		r.Event(ray, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the statefulset %s: %v", sts.Name, err))
+		return nil, err
+	}
+
+	if changes := diffStatefulSet(sts, found); len(changes) != 0 {
+		r.Log.V(1).Info("Updating StatefulSet", "namespace", sts.Namespace, "name", sts.Name,
+			"changes", changes)
+		updated := mergeStatefulSet(sts, found)
+		err = r.Update(context.TODO(), updated)
+		if err != nil {
+			r.Log.Error(err, "Failed to update the statefulset")
+			r.Event(ray, consts.EventWarning, consts.ReasonUpdate,
+				fmt.Sprintf("Failed to update the statefulset %s: %v", sts.Name, err))
+			return nil, err
+		}
+		r.Event(ray, consts.EventNormal, consts.ReasonUpdate,
+			fmt.Sprintf("Successfully update the statefulset %s, changed: %s",
+				sts.Name, strings.Join(changes, ", ")))
+		return updated, nil
+	}
+	return found, nil
+}
+
+// createOrRecreatePod creates the pod, or deletes it if it is changed since most of the
+// pod spec is immutable. The pod is created again when the deletion is observed.
+func (r *RayReconciler) createOrRecreatePod(ray *rayv1.Ray, pod *corev1.Pod) (*corev1.Pod, error) {
+	found := &corev1.Pod{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		r.Log.V(1).Info("Creating Pod", "namespace", pod.Namespace, "name", pod.Name)
+		err = r.Create(context.TODO(), pod)
+		if err != nil {
+			r.Log.Error(err, "Failed to create the pod")
+			r.Event(ray, consts.EventWarning, consts.ReasonCreate,
+				fmt.Sprintf("Failed to create the pod %s: %v", pod.Name, err))
+			return nil, err
+		}
+		r.Event(ray, consts.EventNormal, consts.ReasonCreate,
+			fmt.Sprintf("Successfully create the pod %s", pod.Name))
+		return pod, nil
+	} else if err != nil {
+		r.Log.Error(err, "Failed to get the pod")
+		r.Event(ray, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the pod %s: %v", pod.Name, err))
+		return nil, err
+	}
+
+	if found.DeletionTimestamp != nil ||
+		pod.Annotations[consts.AnnotationSpecHash] == found.Annotations[consts.AnnotationSpecHash] {
+		return found, nil
+	}
+	changes := diffPodTemplate(&corev1.PodTemplateSpec{ObjectMeta: pod.ObjectMeta, Spec: pod.Spec},
+		&corev1.PodTemplateSpec{ObjectMeta: found.ObjectMeta, Spec: found.Spec})
+	if len(changes) == 0 {
+		changes = append(changes, "spec")
+	}
+	r.Log.V(1).Info("Recreating Pod", "namespace", pod.Namespace, "name", pod.Name,
+		"changes", changes)
+	if err := r.Delete(context.TODO(), found); err != nil && !errors.IsNotFound(err) {
+		r.Log.Error(err, "Failed to delete the pod")
+		r.Event(ray, consts.EventWarning, consts.ReasonUpdate,
+			fmt.Sprintf("Failed to recreate the pod %s: %v", pod.Name, err))
+		return nil, err
+	}
+	r.Event(ray, consts.EventNormal, consts.ReasonUpdate,
+		fmt.Sprintf("Successfully delete the pod %s to recreate it, changed: %s",
+			pod.Name, strings.Join(changes, ", ")))
+	return found, nil
+}
+
+// mergeService merges the desired service onto the actual one. The fields set by the
+// apiserver or other controllers, e.g. resourceVersion, clusterIP and node ports, are kept.
+func mergeService(desired *corev1.Service, actual *corev1.Service) *corev1.Service {
+	merged := actual.DeepCopy()
+	mergeManagedMetadata(desired, merged)
+	merged.Spec.Selector = desired.Spec.Selector
+	merged.Spec.Type = desired.Spec.Type
+	// The node ports are allocated only for the NodePort and LoadBalancer services, and
+	// must be cleared together with the external traffic policy for the others.
+	exposed := merged.Spec.Type == corev1.ServiceTypeNodePort ||
+		merged.Spec.Type == corev1.ServiceTypeLoadBalancer
+	if !exposed {
+		merged.Spec.ExternalTrafficPolicy = ""
+		merged.Spec.HealthCheckNodePort = 0
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(desired.Spec.Ports))
+	for _, p := range desired.Spec.Ports {
+		if !exposed {
+			p.NodePort = 0
+			ports = append(ports, p)
+			continue
+		}
+		for _, ap := range actual.Spec.Ports {
+			if p.NodePort == 0 && ap.Name == p.Name && ap.Port == p.Port {
+				p.NodePort = ap.NodePort
+			}
+		}
+		ports = append(ports, p)
+	}
+	merged.Spec.Ports = ports
+	return merged
+}
+
+// mergeDeployment merges the desired deployment onto the actual one. The fields set by the
+// apiserver or other controllers, e.g. resourceVersion, their annotations and replicas when
+// they are managed externally, are kept.
+func mergeDeployment(desired *appsv1.Deployment, actual *appsv1.Deployment) *appsv1.Deployment {
+	merged := actual.DeepCopy()
+	mergeManagedMetadata(desired, merged)
+	if !isExternalReplicas(desired) {
+		// The annotation is removed once the replicas are managed by the Ray again.
+		delete(merged.Annotations, consts.AnnotationExternalReplicas)
+		if desired.Spec.Replicas != nil {
+			merged.Spec.Replicas = desired.Spec.Replicas
+		}
+	}
+
+	template := desired.Spec.Template.DeepCopy()
+	template.Labels = mergeStringMap(actual.Spec.Template.Labels, desired.Spec.Template.Labels)
+	template.Annotations = mergeStringMap(actual.Spec.Template.Annotations,
+		desired.Spec.Template.Annotations)
+	merged.Spec.Template = *template
+	return merged
+}
+
+// isExternalReplicas returns true if the replicas of the deployment are managed by
+// another controller, thus the replicas are only set when it is created.
+func isExternalReplicas(deploy *appsv1.Deployment) bool {
+	return deploy.Annotations[consts.AnnotationExternalReplicas] == "true"
+}
+
+// mergeStatefulSet merges the desired statefulset onto the actual one like mergeDeployment.
+func mergeStatefulSet(desired *appsv1.StatefulSet, actual *appsv1.StatefulSet) *appsv1.StatefulSet {
+	merged := actual.DeepCopy()
+	mergeManagedMetadata(desired, merged)
+	if desired.Spec.Replicas != nil {
+		merged.Spec.Replicas = desired.Spec.Replicas
+	}
+
+	template := desired.Spec.Template.DeepCopy()
+	template.Labels = mergeStringMap(actual.Spec.Template.Labels, desired.Spec.Template.Labels)
+	template.Annotations = mergeStringMap(actual.Spec.Template.Annotations,
+		desired.Spec.Template.Annotations)
+	merged.Spec.Template = *template
+	return merged
+}
+
+// mergeManagedMetadata merges the labels and annotations of the desired object onto the
+// merged one, which is a copy of the actual object. The labels and annotations set by the
+// users or other controllers are kept, while the ones set by the operator before but not
+// desired anymore are deleted. The desired keys are recorded as the managed ones.
+func mergeManagedMetadata(desired, merged metav1.Object) {
+	labels := mergeStringMap(merged.GetLabels(), desired.GetLabels())
+	annotations := mergeStringMap(merged.GetAnnotations(), desired.GetAnnotations())
+	for _, k := range managedKeys(merged, consts.AnnotationManagedLabels) {
+		if _, ok := desired.GetLabels()[k]; !ok {
+			delete(labels, k)
+		}
 	}
-	for i := range new.Spec.Ports {
-		if new.Spec.Ports[i].Port != old.Spec.Ports[i].Port {
-			return true
+	for _, k := range managedKeys(merged, consts.AnnotationManagedAnnotations) {
+		if _, ok := desired.GetAnnotations()[k]; !ok {
+			delete(annotations, k)
 		}
 	}
-	return false
+	merged.SetLabels(labels)
+	merged.SetAnnotations(annotations)
+	setManagedKeys(desired, merged)
 }
 
-// doDeploymentChanged checks if a deployment should be updated. We will update it if the replicas
-// or the resources are changed.
-func doDeploymentChanged(new *appsv1.Deployment, old *appsv1.Deployment) bool {
-	if *new.Spec.Replicas != *old.Spec.Replicas {
-		return true
+// setManagedKeys records the keys of the labels and annotations of the desired object
+// as the managed ones on the object.
+func setManagedKeys(desired, obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
 	}
-	newContainers := new.Spec.Template.Spec.Containers
-	oldContainers := old.Spec.Template.Spec.Containers
-	for i := range newContainers {
-		if !equality.Semantic.DeepEqual(newContainers[i].Resources, oldContainers[i].Resources) {
-			return true
+	annotations[consts.AnnotationManagedLabels] = joinKeys(desired.GetLabels())
+	annotations[consts.AnnotationManagedAnnotations] = joinKeys(desired.GetAnnotations())
+	obj.SetAnnotations(annotations)
+}
+
+// managedKeys returns the managed keys recorded in the annotation of the object.
+func managedKeys(obj metav1.Object, annotation string) []string {
+	value := obj.GetAnnotations()[annotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// joinKeys returns the sorted keys of the map separated by commas. The keys recording
+// the managed keys are excluded.
+func joinKeys(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if k == consts.AnnotationManagedLabels || k == consts.AnnotationManagedAnnotations {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// mergeStringMap returns a new map which contains all entries of the actual map,
+// overridden by the desired map.
+func mergeStringMap(actual, desired map[string]string) map[string]string {
+	if actual == nil && desired == nil {
+		return nil
+	}
+	merged := map[string]string{}
+	for k, v := range actual {
+		merged[k] = v
+	}
+	for k, v := range desired {
+		merged[k] = v
+	}
+	return merged
+}
+
+// deleteStaleWorkerDeployments deletes the worker deployments owned by the Ray
+// which are not desired anymore.
+func (r *RayReconciler) deleteStaleWorkerDeployments(ray *rayv1.Ray,
+	desired []*appsv1.Deployment) error {
+	deploys := &appsv1.DeploymentList{}
+	if err := r.List(context.TODO(), deploys, client.InNamespace(ray.Namespace),
+		client.MatchingLabels(map[string]string{consts.LabelRay: ray.Name})); err != nil {
+		r.Log.Error(err, "Failed to list the deployments")
+		r.Event(ray, consts.EventWarning, consts.ReasonDelete,
+			fmt.Sprintf("Failed to list the deployments: %v", err))
+		return err
+	}
+
+	desiredNames := map[string]bool{}
+	for _, d := range desired {
+		desiredNames[d.Name] = true
+	}
+	for i := range deploys.Items {
+		deploy := &deploys.Items[i]
+		if _, ok := deploy.Spec.Template.Labels[consts.LabelRayWorker]; !ok ||
+			desiredNames[deploy.Name] || !metav1.IsControlledBy(deploy, ray) {
+			continue
+		}
+		r.Log.V(1).Info("Deleting Deployment", "namespace", deploy.Namespace, "name", deploy.Name)
+		if err := r.Delete(context.TODO(), deploy); err != nil && !errors.IsNotFound(err) {
+			r.Log.Error(err, "Failed to delete the deployment")
+			r.Event(ray, consts.EventWarning, consts.ReasonDelete,
+				fmt.Sprintf("Failed to delete the deployment %s: %v", deploy.Name, err))
+			return err
+		}
+		r.Event(ray, consts.EventNormal, consts.ReasonDelete,
+			fmt.Sprintf("Successfully delete the deployment %s", deploy.Name))
+	}
+	return nil
+}
+
+// diffService returns the changes between the desired and the actual service. The
+// service should be updated if there is any change.
+func diffService(new *corev1.Service, old *corev1.Service) []string {
+	if new.Annotations[consts.AnnotationSpecHash] == old.Annotations[consts.AnnotationSpecHash] {
+		return nil
+	}
+	changes := []string{}
+	if new.Spec.Type != old.Spec.Type {
+		changes = append(changes, "type")
+	}
+	if !equality.Semantic.DeepEqual(new.Spec.Selector, old.Spec.Selector) {
+		changes = append(changes, "selector")
+	}
+	if len(new.Spec.Ports) != len(old.Spec.Ports) {
+		changes = append(changes, "ports")
+	} else {
+		for i := range new.Spec.Ports {
+			if new.Spec.Ports[i].Name != old.Spec.Ports[i].Name ||
+				new.Spec.Ports[i].Port != old.Spec.Ports[i].Port ||
+				new.Spec.Ports[i].TargetPort != old.Spec.Ports[i].TargetPort {
+				changes = append(changes, "ports")
+				break
+			}
+		}
+	}
+	if len(changes) == 0 {
+		changes = append(changes, "spec")
+	}
+	return changes
+}
+
+// diffDeployment returns the changes between the desired and the actual deployment. The
+// deployment should be updated if the replicas or the hash of the pod template are changed.
+func diffDeployment(new *appsv1.Deployment, old *appsv1.Deployment) []string {
+	changes := []string{}
+	if new.Spec.Replicas != nil && old.Spec.Replicas != nil && !isExternalReplicas(new) &&
+		*new.Spec.Replicas != *old.Spec.Replicas {
+		changes = append(changes, "replicas")
+	}
+	if isExternalReplicas(new) != isExternalReplicas(old) {
+		changes = append(changes, "annotations")
+	}
+	if new.Annotations[consts.AnnotationSpecHash] == old.Annotations[consts.AnnotationSpecHash] {
+		return changes
+	}
+	templateChanges := diffPodTemplate(&new.Spec.Template, &old.Spec.Template)
+	if len(templateChanges) == 0 {
+		// The actual template is defaulted by the apiserver, thus the changes
+		// may not be found by comparing the fields.
+		templateChanges = append(templateChanges, "template")
+	}
+	return append(changes, templateChanges...)
+}
+
+// diffStatefulSet returns the changes between the desired and the actual statefulset
+// like diffDeployment.
+func diffStatefulSet(new *appsv1.StatefulSet, old *appsv1.StatefulSet) []string {
+	changes := []string{}
+	if new.Spec.Replicas != nil && old.Spec.Replicas != nil &&
+		*new.Spec.Replicas != *old.Spec.Replicas {
+		changes = append(changes, "replicas")
+	}
+	if new.Annotations[consts.AnnotationSpecHash] == old.Annotations[consts.AnnotationSpecHash] {
+		return changes
+	}
+	templateChanges := diffPodTemplate(&new.Spec.Template, &old.Spec.Template)
+	if len(templateChanges) == 0 {
+		templateChanges = append(templateChanges, "template")
+	}
+	return append(changes, templateChanges...)
+}
+
+// diffPodTemplate returns the fields changed in the pod template, it is
+// used to describe the update in the event.
+func diffPodTemplate(new *corev1.PodTemplateSpec, old *corev1.PodTemplateSpec) []string {
+	changes := []string{}
+	if !equality.Semantic.DeepEqual(new.Labels, old.Labels) {
+		changes = append(changes, "labels")
+	}
+	if len(new.Spec.Volumes) != len(old.Spec.Volumes) {
+		changes = append(changes, "volumes")
+	} else {
+		for i := range new.Spec.Volumes {
+			if new.Spec.Volumes[i].Name != old.Spec.Volumes[i].Name {
+				changes = append(changes, "volumes")
+				break
+			}
+		}
+	}
+	if !equality.Semantic.DeepEqual(new.Spec.NodeSelector, old.Spec.NodeSelector) {
+		changes = append(changes, "nodeSelector")
+	}
+	if !equality.Semantic.DeepEqual(new.Spec.Tolerations, old.Spec.Tolerations) {
+		changes = append(changes, "tolerations")
+	}
+	if len(new.Spec.Containers) != len(old.Spec.Containers) {
+		return append(changes, "containers")
+	}
+	for i := range new.Spec.Containers {
+		n, o := &new.Spec.Containers[i], &old.Spec.Containers[i]
+		if n.Name != o.Name {
+			changes = append(changes, "containers")
+			continue
+		}
+		if n.Image != o.Image {
+			changes = append(changes, fmt.Sprintf("%s.image", n.Name))
+		}
+		if !equality.Semantic.DeepEqual(n.Command, o.Command) ||
+			!equality.Semantic.DeepEqual(n.Args, o.Args) {
+			changes = append(changes, fmt.Sprintf("%s.command", n.Name))
+		}
+		if len(n.Env) != len(o.Env) {
+			changes = append(changes, fmt.Sprintf("%s.env", n.Name))
+		} else {
+			for j := range n.Env {
+				if n.Env[j].Name != o.Env[j].Name || n.Env[j].Value != o.Env[j].Value {
+					changes = append(changes, fmt.Sprintf("%s.env", n.Name))
+					break
+				}
+			}
+		}
+		if !equality.Semantic.DeepEqual(n.Resources, o.Resources) {
+			changes = append(changes, fmt.Sprintf("%s.resources", n.Name))
+		}
+		if len(n.VolumeMounts) != len(o.VolumeMounts) {
+			changes = append(changes, fmt.Sprintf("%s.volumeMounts", n.Name))
 		}
 	}
-	return false
+	return changes
 }
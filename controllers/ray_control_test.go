@@ -0,0 +1,480 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+	"github.com/kubeflow/ray-operator/pkg/validator"
+)
+
+func newTestReconciler(objs ...runtime.Object) *RayReconciler {
+	s := runtime.NewScheme()
+	_ = scheme.AddToScheme(s)
+	_ = rayv1.AddToScheme(s)
+	recorder := record.NewFakeRecorder(100)
+	return &RayReconciler{
+		Client:        fake.NewFakeClientWithScheme(s, objs...),
+		EventRecorder: recorder,
+		Validator:     validator.New(recorder, logf.NullLogger{}),
+		Composer:      composer.New(recorder, logf.NullLogger{}, s),
+		Log:           logf.NullLogger{},
+	}
+}
+
+func newTestRay(name string) *rayv1.Ray {
+	ray := &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID(name + "-uid"),
+		},
+		Spec: rayv1.RaySpec{
+			RayVersion: "2.9.0",
+		},
+	}
+	ray.Default()
+	return ray
+}
+
+func newTestWorkerDeployment(ray *rayv1.Ray, group string, labels map[string]string,
+	owned bool) *appsv1.Deployment {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      composer.GetWorkerName(ray.Name, group),
+			Namespace: ray.Namespace,
+			Labels:    labels,
+		},
+	}
+	deploy.Spec.Template.Labels = composer.GetWorkerPodLabels(ray.Name, group)
+	if owned {
+		deploy.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(ray, rayv1.GroupVersion.WithKind("Ray")),
+		}
+	}
+	return deploy
+}
+
+func TestDeleteStaleWorkerDeployments(t *testing.T) {
+	ray := newTestRay("test")
+	other := newTestRay("other")
+	rayLabels := map[string]string{consts.LabelRay: ray.Name}
+
+	desired := newTestWorkerDeployment(ray, "cpu", rayLabels, true)
+	stale := newTestWorkerDeployment(ray, "gpu", rayLabels, true)
+	// The deployment is labeled with the Ray but created by someone else.
+	notOwned := newTestWorkerDeployment(ray, "manual", rayLabels, false)
+	// The deployment is owned by the Ray but not labeled with it, e.g. the label is
+	// overridden by the user.
+	notLabeled := newTestWorkerDeployment(ray, "unlabeled", nil, true)
+	otherRay := newTestWorkerDeployment(other, "gpu",
+		map[string]string{consts.LabelRay: other.Name}, true)
+
+	r := newTestReconciler(desired, stale, notOwned, notLabeled, otherRay)
+	if err := r.deleteStaleWorkerDeployments(ray, []*appsv1.Deployment{desired}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, c := range []struct {
+		deploy  *appsv1.Deployment
+		deleted bool
+	}{
+		{desired, false},
+		{stale, true},
+		{notOwned, false},
+		{notLabeled, false},
+		{otherRay, false},
+	} {
+		err := r.Get(context.TODO(), types.NamespacedName{
+			Name: c.deploy.Name, Namespace: c.deploy.Namespace}, &appsv1.Deployment{})
+		if errors.IsNotFound(err) != c.deleted {
+			t.Errorf("expected the deployment %s deleted %v, got %v", c.deploy.Name, c.deleted, err)
+		}
+	}
+}
+
+func newTestService(hash string, port int32) *corev1.Service {
+	svc := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{consts.LabelRay: "test"},
+			Ports: []corev1.ServicePort{
+				{Name: "redis", Port: port, TargetPort: intstr.FromInt(int(port))},
+			},
+		},
+	}
+	if hash != "" {
+		svc.Annotations = map[string]string{consts.AnnotationSpecHash: hash}
+	}
+	return svc
+}
+
+func TestDiffService(t *testing.T) {
+	cases := []struct {
+		name     string
+		desired  *corev1.Service
+		actual   *corev1.Service
+		expected []string
+	}{
+		{
+			name:     "equal",
+			desired:  newTestService("a", 6379),
+			actual:   newTestService("a", 6379),
+			expected: nil,
+		},
+		{
+			name:     "ports changed",
+			desired:  newTestService("b", 6380),
+			actual:   newTestService("a", 6379),
+			expected: []string{"ports"},
+		},
+		{
+			// The fields cannot be compared precisely, e.g. they are defaulted by the apiserver.
+			name:     "hash changed",
+			desired:  newTestService("b", 6379),
+			actual:   newTestService("a", 6379),
+			expected: []string{"spec"},
+		},
+		{
+			name:     "missing annotation",
+			desired:  newTestService("a", 6379),
+			actual:   newTestService("", 6379),
+			expected: []string{"spec"},
+		},
+		{
+			name:     "type changed",
+			desired:  newTestService("b", 6379),
+			actual:   withServiceType(newTestService("a", 6379), corev1.ServiceTypeNodePort),
+			expected: []string{"type"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if changes := diffService(c.desired, c.actual); !reflect.DeepEqual(changes, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, changes)
+			}
+		})
+	}
+}
+
+func withServiceType(svc *corev1.Service, serviceType corev1.ServiceType) *corev1.Service {
+	svc.Spec.Type = serviceType
+	return svc
+}
+
+// withNodePort sets the node port and the external traffic policy allocated by the apiserver.
+func withNodePort(svc *corev1.Service, nodePort int32) *corev1.Service {
+	svc.Spec.Ports[0].NodePort = nodePort
+	svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+	return svc
+}
+
+func TestMergeService(t *testing.T) {
+	cases := []struct {
+		name             string
+		desired          *corev1.Service
+		actual           *corev1.Service
+		expectedType     corev1.ServiceType
+		expectedNodePort int32
+	}{
+		{
+			name:             "node port kept",
+			desired:          withServiceType(newTestService("b", 6379), corev1.ServiceTypeNodePort),
+			actual:           withNodePort(withServiceType(newTestService("a", 6379), corev1.ServiceTypeNodePort), 30001),
+			expectedType:     corev1.ServiceTypeNodePort,
+			expectedNodePort: 30001,
+		},
+		{
+			name:             "node port kept for load balancer",
+			desired:          withServiceType(newTestService("b", 6379), corev1.ServiceTypeLoadBalancer),
+			actual:           withNodePort(withServiceType(newTestService("a", 6379), corev1.ServiceTypeNodePort), 30001),
+			expectedType:     corev1.ServiceTypeLoadBalancer,
+			expectedNodePort: 30001,
+		},
+		{
+			// Removing serviceType reverts the service to ClusterIP without the node ports.
+			name:         "reverted to cluster ip",
+			desired:      newTestService("b", 6379),
+			actual:       withNodePort(withServiceType(newTestService("a", 6379), corev1.ServiceTypeNodePort), 30001),
+			expectedType: corev1.ServiceTypeClusterIP,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			merged := mergeService(c.desired, c.actual)
+			if merged.Spec.Type != c.expectedType {
+				t.Errorf("expected the type %s, got %s", c.expectedType, merged.Spec.Type)
+			}
+			if merged.Spec.Ports[0].NodePort != c.expectedNodePort {
+				t.Errorf("expected the node port %d, got %d", c.expectedNodePort, merged.Spec.Ports[0].NodePort)
+			}
+			if c.expectedNodePort == 0 && merged.Spec.ExternalTrafficPolicy != "" {
+				t.Errorf("expected no external traffic policy, got %s", merged.Spec.ExternalTrafficPolicy)
+			}
+		})
+	}
+}
+
+func TestMergeManagedMetadata(t *testing.T) {
+	desired := &metav1.ObjectMeta{
+		Labels:      map[string]string{"ray": "test"},
+		Annotations: map[string]string{"a": "1"},
+	}
+	actual := &metav1.ObjectMeta{
+		Labels: map[string]string{"ray": "test", "stale": "true", "external": "true"},
+		Annotations: map[string]string{
+			"a":                                 "0",
+			"b":                                 "1",
+			"external":                          "true",
+			consts.AnnotationManagedLabels:      "ray,stale",
+			consts.AnnotationManagedAnnotations: "a,b",
+		},
+	}
+	mergeManagedMetadata(desired, actual)
+
+	expectedLabels := map[string]string{"ray": "test", "external": "true"}
+	if !reflect.DeepEqual(actual.Labels, expectedLabels) {
+		t.Errorf("expected the labels %v, got %v", expectedLabels, actual.Labels)
+	}
+	expectedAnnotations := map[string]string{
+		"a":                                 "1",
+		"external":                          "true",
+		consts.AnnotationManagedLabels:      "ray",
+		consts.AnnotationManagedAnnotations: "a",
+	}
+	if !reflect.DeepEqual(actual.Annotations, expectedAnnotations) {
+		t.Errorf("expected the annotations %v, got %v", expectedAnnotations, actual.Annotations)
+	}
+}
+
+func TestCreateOrUpdateServiceRemovesAnnotation(t *testing.T) {
+	ray := newTestRay("test")
+	r := newTestReconciler(ray)
+	desired := newTestService("a", 6379)
+	desired.Name = "test-head"
+	desired.Namespace = ray.Namespace
+	desired.Annotations["lb.example.com/internal"] = "true"
+	if _, err := r.createOrUpdateService(ray, desired.DeepCopy()); err != nil {
+		t.Fatalf("failed to create the service: %v", err)
+	}
+
+	// Another controller annotates the service.
+	actual := &corev1.Service{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, actual); err != nil {
+		t.Fatal(err)
+	}
+	actual.Annotations["example.com/owner"] = "team-a"
+	if err := r.Update(context.TODO(), actual); err != nil {
+		t.Fatal(err)
+	}
+
+	// The annotation is removed from the desired service.
+	desired = newTestService("b", 6379)
+	desired.Name = "test-head"
+	desired.Namespace = ray.Namespace
+	updated, err := r.createOrUpdateService(ray, desired)
+	if err != nil {
+		t.Fatalf("failed to update the service: %v", err)
+	}
+	if _, ok := updated.Annotations["lb.example.com/internal"]; ok {
+		t.Error("expected the removed annotation to be deleted")
+	}
+	if updated.Annotations["example.com/owner"] != "team-a" {
+		t.Errorf("expected the annotation of the other controller to be kept, got %v", updated.Annotations)
+	}
+	if updated.Annotations[consts.AnnotationManagedAnnotations] != consts.AnnotationSpecHash {
+		t.Errorf("expected the managed annotations %s, got %s", consts.AnnotationSpecHash,
+			updated.Annotations[consts.AnnotationManagedAnnotations])
+	}
+}
+
+func newTestDeployment(hash string, replicas int32, image string) *appsv1.Deployment {
+	deploy := &appsv1.Deployment{
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: consts.ContainerRayWorker, Image: image},
+					},
+				},
+			},
+		},
+	}
+	if hash != "" {
+		deploy.Annotations = map[string]string{consts.AnnotationSpecHash: hash}
+	}
+	return deploy
+}
+
+func TestDiffDeployment(t *testing.T) {
+	cases := []struct {
+		name     string
+		desired  *appsv1.Deployment
+		actual   *appsv1.Deployment
+		expected []string
+	}{
+		{
+			name:     "equal",
+			desired:  newTestDeployment("a", 1, "ray:1"),
+			actual:   newTestDeployment("a", 1, "ray:1"),
+			expected: []string{},
+		},
+		{
+			name:     "replicas changed",
+			desired:  newTestDeployment("a", 2, "ray:1"),
+			actual:   newTestDeployment("a", 1, "ray:1"),
+			expected: []string{"replicas"},
+		},
+		{
+			name:     "image changed",
+			desired:  newTestDeployment("b", 1, "ray:2"),
+			actual:   newTestDeployment("a", 1, "ray:1"),
+			expected: []string{consts.ContainerRayWorker + ".image"},
+		},
+		{
+			name:     "replicas and template changed",
+			desired:  newTestDeployment("b", 2, "ray:2"),
+			actual:   newTestDeployment("a", 1, "ray:1"),
+			expected: []string{"replicas", consts.ContainerRayWorker + ".image"},
+		},
+		{
+			name:     "missing annotation",
+			desired:  newTestDeployment("a", 1, "ray:1"),
+			actual:   newTestDeployment("", 1, "ray:1"),
+			expected: []string{"template"},
+		},
+		{
+			name:     "external replicas",
+			desired:  withExternalReplicas(newTestDeployment("a", 1, "ray:1")),
+			actual:   withExternalReplicas(newTestDeployment("a", 5, "ray:1")),
+			expected: []string{},
+		},
+		{
+			name:     "external replicas disabled",
+			desired:  newTestDeployment("a", 1, "ray:1"),
+			actual:   withExternalReplicas(newTestDeployment("a", 1, "ray:1")),
+			expected: []string{"annotations"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if changes := diffDeployment(c.desired, c.actual); !reflect.DeepEqual(changes, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, changes)
+			}
+		})
+	}
+}
+
+func withExternalReplicas(deploy *appsv1.Deployment) *appsv1.Deployment {
+	deploy.Annotations[consts.AnnotationExternalReplicas] = "true"
+	return deploy
+}
+
+func TestMergeDeployment(t *testing.T) {
+	cases := []struct {
+		name                string
+		desired             *appsv1.Deployment
+		actual              *appsv1.Deployment
+		expectedReplicas    int32
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:                "replicas from the Ray",
+			desired:             newTestDeployment("b", 2, "ray:2"),
+			actual:              newTestDeployment("a", 5, "ray:1"),
+			expectedReplicas:    2,
+			expectedAnnotations: map[string]string{consts.AnnotationSpecHash: "b"},
+		},
+		{
+			name:             "external replicas",
+			desired:          withExternalReplicas(newTestDeployment("b", 2, "ray:2")),
+			actual:           withExternalReplicas(newTestDeployment("a", 5, "ray:1")),
+			expectedReplicas: 5,
+			expectedAnnotations: map[string]string{
+				consts.AnnotationSpecHash:         "b",
+				consts.AnnotationExternalReplicas: "true",
+			},
+		},
+		{
+			name:                "external replicas disabled",
+			desired:             newTestDeployment("a", 2, "ray:1"),
+			actual:              withExternalReplicas(newTestDeployment("a", 5, "ray:1")),
+			expectedReplicas:    2,
+			expectedAnnotations: map[string]string{consts.AnnotationSpecHash: "a"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.actual.ResourceVersion = "10"
+			c.actual.Annotations["injected"] = "true"
+			c.actual.Spec.Template.Annotations = map[string]string{"sidecar": "injected"}
+			c.expectedAnnotations["injected"] = "true"
+
+			merged := mergeDeployment(c.desired, c.actual)
+			if *merged.Spec.Replicas != c.expectedReplicas {
+				t.Errorf("expected %d replicas, got %d", c.expectedReplicas, *merged.Spec.Replicas)
+			}
+			// The managed keys are covered by TestMergeManagedMetadata.
+			delete(merged.Annotations, consts.AnnotationManagedLabels)
+			delete(merged.Annotations, consts.AnnotationManagedAnnotations)
+			if !reflect.DeepEqual(merged.Annotations, c.expectedAnnotations) {
+				t.Errorf("expected the annotations %v, got %v", c.expectedAnnotations, merged.Annotations)
+			}
+			if merged.ResourceVersion != "10" {
+				t.Errorf("expected the resourceVersion to be kept, got %q", merged.ResourceVersion)
+			}
+			if merged.Spec.Template.Annotations["sidecar"] != "injected" {
+				t.Errorf("expected the template annotations to be kept, got %v",
+					merged.Spec.Template.Annotations)
+			}
+			if merged.Spec.Template.Spec.Containers[0].Image != c.desired.Spec.Template.Spec.Containers[0].Image {
+				t.Errorf("expected the desired template, got %v", merged.Spec.Template.Spec.Containers)
+			}
+		})
+	}
+}
+
+func TestSyncHeadKindChanged(t *testing.T) {
+	ray := newTestRay("test")
+	ray.Spec.Head.WorkloadKind = rayv1.HeadWorkloadStatefulSet
+	r := newTestReconciler()
+	if _, err := r.syncHead(ray); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	governing := types.NamespacedName{
+		Name: composer.GetHeadGoverningServiceName(ray.Name), Namespace: ray.Namespace}
+	if err := r.Get(context.TODO(), governing, &corev1.Service{}); err != nil {
+		t.Fatalf("expected the governing service to be created, got %v", err)
+	}
+
+	ray.Spec.Head.WorkloadKind = rayv1.HeadWorkloadDeployment
+	if _, err := r.syncHead(ray); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	head := types.NamespacedName{Name: composer.GetHeadName(ray.Name), Namespace: ray.Namespace}
+	if err := r.Get(context.TODO(), head, &appsv1.StatefulSet{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the StatefulSet to be deleted, got %v", err)
+	}
+	if err := r.Get(context.TODO(), governing, &corev1.Service{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the governing service to be deleted, got %v", err)
+	}
+	if err := r.Get(context.TODO(), head, &appsv1.Deployment{}); err != nil {
+		t.Errorf("expected the Deployment to be created, got %v", err)
+	}
+}
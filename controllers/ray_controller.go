@@ -18,10 +18,13 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -31,7 +34,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/autoscaler"
 	"github.com/kubeflow/ray-operator/pkg/composer"
+	"github.com/kubeflow/ray-operator/pkg/metrics"
+	"github.com/kubeflow/ray-operator/pkg/prober"
 	"github.com/kubeflow/ray-operator/pkg/validator"
 )
 
@@ -44,13 +50,25 @@ type RayReconciler struct {
 	client.Client
 	record.EventRecorder
 
-	Validator validator.Interface
-	Composer  composer.Interface
-	Log       logr.Logger
+	Validator  validator.Interface
+	Composer   composer.Interface
+	Autoscaler autoscaler.Interface
+	// Prober probes the head of the Ray, the head is not probed if it is nil.
+	Prober prober.Interface
+	// NodeSource gets the nodes registered with the head, they are not checked if it is nil.
+	NodeSource prober.NodeSource
+	Log        logr.Logger
 }
 
 // +kubebuilder:rbac:groups=ray.kubeflow.org,resources=rays,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ray.kubeflow.org,resources=rays/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=scheduling.volcano.sh,resources=podgroups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups,verbs=get;list;watch;create;update;patch;delete
 
 func (r *RayReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	_ = context.Background()
@@ -63,15 +81,24 @@ func (r *RayReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		if errors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			metrics.Forget(req.Namespace, req.Name)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
-	return r.sync(instance)
+
+	start := time.Now()
+	result, err := r.sync(instance)
+	metrics.ObserveReconcile(req.Namespace, req.Name, start, err != nil)
+	if isTerminalError(err) {
+		// Do not requeue the requests since we cannot deal with it.
+		return result, nil
+	}
+	return result, err
 }
 
-// SetupWithManager setups the manager and watch the deployment resource.
+// SetupWithManager setups the manager and watch the resources of the head and the workers.
 func (r *RayReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&rayv1.Ray{}).
@@ -80,10 +107,30 @@ func (r *RayReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				IsController: true,
 				OwnerType:    &rayv1.Ray{},
 			}).
+		Watches(&source.Kind{Type: &appsv1.StatefulSet{}},
+			&handler.EnqueueRequestForOwner{
+				IsController: true,
+				OwnerType:    &rayv1.Ray{},
+			}).
+		Watches(&source.Kind{Type: &corev1.Pod{}},
+			&handler.EnqueueRequestForOwner{
+				IsController: true,
+				OwnerType:    &rayv1.Ray{},
+			}).
+		Watches(&source.Kind{Type: &batchv1.Job{}},
+			&handler.EnqueueRequestForOwner{
+				IsController: true,
+				OwnerType:    &rayv1.Ray{},
+			}).
 		Watches(&source.Kind{Type: &corev1.Service{}},
 			&handler.EnqueueRequestForOwner{
 				IsController: true,
 				OwnerType:    &rayv1.Ray{},
 			}).
+		Watches(&source.Kind{Type: &networkingv1beta1.Ingress{}},
+			&handler.EnqueueRequestForOwner{
+				IsController: true,
+				OwnerType:    &rayv1.Ray{},
+			}).
 		Complete(r)
 }
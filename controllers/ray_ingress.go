@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// syncHeadIngress creates or updates the Ingress of the head if it is specified, or
+// deletes the Ingress owned by the Ray otherwise. It returns the actual Ingress, which
+// is nil if the Ingress is not specified.
+func (r *RayReconciler) syncHeadIngress(ray *rayv1.Ray) (*networkingv1beta1.Ingress, error) {
+	if ray.Spec.Head.Ingress == nil {
+		return nil, r.deleteStaleIngress(ray)
+	}
+	desired, err := r.Composer.DesiredHeadIngress(ray)
+	if err != nil {
+		return nil, newTerminalError(consts.ReasonComposeFailed, err)
+	}
+
+	found := &networkingv1beta1.Ingress{}
+	err = r.Get(context.TODO(), types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		r.Log.V(1).Info("Creating Ingress", "namespace", desired.Namespace, "name", desired.Name)
+		setManagedKeys(desired, desired)
+		if err := r.Create(context.TODO(), desired); err != nil {
+			r.Log.Error(err, "Failed to create the ingress")
+			r.Event(ray, consts.EventWarning, consts.ReasonCreate,
+				fmt.Sprintf("Failed to create the ingress %s: %v", desired.Name, err))
+			return nil, err
+		}
+		r.Event(ray, consts.EventNormal, consts.ReasonCreate,
+			fmt.Sprintf("Successfully create the ingress %s", desired.Name))
+		return desired, nil
+	} else if err != nil {
+		r.Log.Error(err, "Failed to get the ingress")
+		r.Event(ray, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the ingress %s: %v", desired.Name, err))
+		return nil, err
+	}
+
+	if desired.Annotations[consts.AnnotationSpecHash] == found.Annotations[consts.AnnotationSpecHash] {
+		return found, nil
+	}
+	r.Log.V(1).Info("Updating ingress", "namespace", desired.Namespace, "name", desired.Name)
+	updated := found.DeepCopy()
+	mergeManagedMetadata(desired, updated)
+	updated.Spec = desired.Spec
+	if err := r.Update(context.TODO(), updated); err != nil {
+		r.Log.Error(err, "Failed to update the ingress")
+		r.Event(ray, consts.EventWarning, consts.ReasonUpdate,
+			fmt.Sprintf("Failed to update the ingress %s: %v", desired.Name, err))
+		return nil, err
+	}
+	r.Event(ray, consts.EventNormal, consts.ReasonUpdate,
+		fmt.Sprintf("Successfully update the ingress %s", desired.Name))
+	return updated, nil
+}
+
+// deleteStaleIngress deletes the Ingress of the head which is owned by the Ray.
+func (r *RayReconciler) deleteStaleIngress(ray *rayv1.Ray) error {
+	name := composer.GetHeadName(ray.Name)
+	ingress := &networkingv1beta1.Ingress{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: ray.Namespace}, ingress)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		r.Log.Error(err, "Failed to get the ingress")
+		r.Event(ray, consts.EventWarning, consts.ReasonDelete,
+			fmt.Sprintf("Failed to get the ingress %s: %v", name, err))
+		return err
+	}
+	if !metav1.IsControlledBy(ingress, ray) || ingress.DeletionTimestamp != nil {
+		return nil
+	}
+	r.Log.V(1).Info("Deleting Ingress", "namespace", ray.Namespace, "name", name)
+	if err := r.Delete(context.TODO(), ingress); err != nil && !errors.IsNotFound(err) {
+		r.Log.Error(err, "Failed to delete the ingress")
+		r.Event(ray, consts.EventWarning, consts.ReasonDelete,
+			fmt.Sprintf("Failed to delete the ingress %s: %v", name, err))
+		return err
+	}
+	r.Event(ray, consts.EventNormal, consts.ReasonDelete,
+		fmt.Sprintf("Successfully delete the ingress %s", name))
+	return nil
+}
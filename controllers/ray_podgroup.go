@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// podGroupPollPeriod is the period to check the phase of the PodGroup, which is not
+// watched since its CRD may not be installed.
+const podGroupPollPeriod = 30 * time.Second
+
+// syncPodGroup creates or updates the PodGroup if the gang scheduling is enabled, after
+// the PodGroup recorded in the status is deleted if it is not desired anymore. It returns
+// the actual PodGroup, which is nil if the gang scheduling is disabled.
+func (r *RayReconciler) syncPodGroup(ray *rayv1.Ray) (*unstructured.Unstructured, error) {
+	if err := r.deleteStalePodGroup(ray); err != nil {
+		return nil, err
+	}
+	if ray.Spec.Scheduling == nil {
+		return nil, nil
+	}
+	desired, err := r.Composer.DesiredPodGroup(ray)
+	if err != nil {
+		return nil, newTerminalError(consts.ReasonComposeFailed, err)
+	}
+
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(desired.GroupVersionKind())
+	err = r.Get(context.TODO(), types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, found)
+	if err != nil && errors.IsNotFound(err) {
+		r.Log.V(1).Info("Creating PodGroup", "namespace", desired.GetNamespace(), "name", desired.GetName())
+		setManagedKeys(desired, desired)
+		if err := r.Create(context.TODO(), desired); err != nil {
+			r.Log.Error(err, "Failed to create the pod group")
+			r.Event(ray, consts.EventWarning, consts.ReasonCreate,
+				fmt.Sprintf("Failed to create the pod group %s: %v", desired.GetName(), err))
+			return nil, err
+		}
+		r.Event(ray, consts.EventNormal, consts.ReasonCreate,
+			fmt.Sprintf("Successfully create the pod group %s", desired.GetName()))
+		return desired, nil
+	} else if err != nil {
+		// A missing PodGroup CRD is reported here as well, the request is retried
+		// until the gang scheduler is installed.
+		r.Log.Error(err, "Failed to get the pod group")
+		r.Event(ray, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the pod group %s: %v", desired.GetName(), err))
+		return nil, err
+	}
+
+	if desired.GetAnnotations()[consts.AnnotationSpecHash] == found.GetAnnotations()[consts.AnnotationSpecHash] {
+		return found, nil
+	}
+	r.Log.V(1).Info("Updating PodGroup", "namespace", desired.GetNamespace(), "name", desired.GetName())
+	updated := found.DeepCopy()
+	mergeManagedMetadata(desired, updated)
+	// The status reported by the scheduler is kept.
+	updated.Object["spec"] = desired.Object["spec"]
+	if err := r.Update(context.TODO(), updated); err != nil {
+		r.Log.Error(err, "Failed to update the pod group")
+		r.Event(ray, consts.EventWarning, consts.ReasonUpdate,
+			fmt.Sprintf("Failed to update the pod group %s: %v", desired.GetName(), err))
+		return nil, err
+	}
+	r.Event(ray, consts.EventNormal, consts.ReasonUpdate,
+		fmt.Sprintf("Successfully update the pod group %s", desired.GetName()))
+	return updated, nil
+}
+
+// deleteStalePodGroup deletes the PodGroup recorded in the status if the gang scheduling
+// is disabled or its provider is changed. A PodGroup which is not recorded is deleted
+// with the Ray by the garbage collector.
+func (r *RayReconciler) deleteStalePodGroup(ray *rayv1.Ray) error {
+	observed := ray.Status.Scheduling
+	if observed == nil || (ray.Spec.Scheduling != nil && ray.Spec.Scheduling.Provider == observed.Provider) {
+		return nil
+	}
+	gvk, err := composer.GetPodGroupGVK(observed.Provider)
+	if err != nil {
+		return nil
+	}
+	name := observed.PodGroupName
+	podGroup := &unstructured.Unstructured{}
+	podGroup.SetGroupVersionKind(gvk)
+	err = r.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: ray.Namespace}, podGroup)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	} else if err != nil {
+		r.Log.Error(err, "Failed to get the pod group")
+		r.Event(ray, consts.EventWarning, consts.ReasonDelete,
+			fmt.Sprintf("Failed to get the pod group %s: %v", name, err))
+		return err
+	}
+	if !metav1.IsControlledBy(podGroup, ray) || podGroup.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	r.Log.V(1).Info("Deleting PodGroup", "namespace", ray.Namespace, "name", name)
+	if err := r.Delete(context.TODO(), podGroup); err != nil && !errors.IsNotFound(err) {
+		r.Log.Error(err, "Failed to delete the pod group")
+		r.Event(ray, consts.EventWarning, consts.ReasonDelete,
+			fmt.Sprintf("Failed to delete the pod group %s: %v", name, err))
+		return err
+	}
+	r.Event(ray, consts.EventNormal, consts.ReasonDelete,
+		fmt.Sprintf("Successfully delete the pod group %s", name))
+	return nil
+}
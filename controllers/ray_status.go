@@ -2,19 +2,28 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
 	"github.com/kubeflow/ray-operator/pkg/consts"
+	"github.com/kubeflow/ray-operator/pkg/metrics"
 )
 
-func (r *RayReconciler) updateStatus(ray *rayv1.Ray,
-	head *appsv1.Deployment, worker *appsv1.Deployment) error {
+func (r *RayReconciler) updateStatus(ray *rayv1.Ray, service *corev1.Service,
+	ingress *networkingv1beta1.Ingress, podGroup *unstructured.Unstructured, head runtime.Object,
+	workers []*appsv1.Deployment) error {
 	old := ray.Status.DeepCopy()
 	status := &ray.Status
 
@@ -27,99 +36,94 @@ func (r *RayReconciler) updateStatus(ray *rayv1.Ray,
 		status.ObservedGeneration = ray.Generation
 	}
 
+	// The terminal errors are resolved since the Ray is reconciled.
+	for _, t := range []rayv1.RayConditionType{rayv1.RayValidationFailed, rayv1.RayReconcileError} {
+		if containConditionType(status.Conditions, t) {
+			createOrUpdateCondition(&status.Conditions, t, corev1.ConditionFalse)
+		}
+	}
+
 	// shouldActive is the number of the components should be active.
-	shouldActive := 2
+	shouldActive := 1 + len(workers)
 	// activeCounter is the number of components which are actually active.
 	activeCounter := 0
 	// running is the number of components which are actually running or pending.
 	running := 0
 
-	// Set the worker status.
-	status.Worker.Replicas = worker.Status.Replicas
-	status.Worker.ReadyReplicas = worker.Status.ReadyReplicas
-	status.Worker.AvailableReplicas = worker.Status.AvailableReplicas
-	status.Worker.UnavailableReplicas = worker.Status.UnavailableReplicas
-	status.Worker.UpdatedReplicas = worker.Status.UpdatedReplicas
-
-	// Set the head status.
-	status.Head.Replicas = head.Status.Replicas
-	status.Head.ReadyReplicas = head.Status.ReadyReplicas
-	status.Head.AvailableReplicas = head.Status.AvailableReplicas
-	status.Head.UnavailableReplicas = head.Status.UnavailableReplicas
-	status.Head.UpdatedReplicas = head.Status.UpdatedReplicas
-
-	// Get the pods belong to the deployment.
-	pods := &corev1.PodList{}
-	if err := r.List(context.TODO(), pods, client.MatchingLabels(map[string]string{
-		consts.LabelRayWorker: worker.Name,
-	})); err != nil {
-		return err
-	}
+	// Set the worker group status.
+	groups := ray.Spec.GetWorkerGroups()
+	groupStatuses := make([]rayv1.WorkerGroupStatus, 0, len(workers))
+	for i, worker := range workers {
+		groupStatus := rayv1.WorkerGroupStatus{
+			Name: groups[i].Name,
+		}
+		// Keep the observed conditions to preserve the transition time.
+		for _, observed := range status.WorkerGroups {
+			if observed.Name == groupStatus.Name {
+				groupStatus.Conditions = observed.Conditions
+			}
+		}
+		setReplicaStatus(&groupStatus.ReplicaStatus, worker)
 
-	if hasDeploymentAvailable(worker) {
-		// Check if the deployment is available.
-		if isDeploymentAvailable(worker) {
-			// If the deployment is active, we set the condition to serving status
-			// and mark it active.
+		active, pending, err := r.syncDeploymentAvailable(&groupStatus.Conditions,
+			rayv1.RayWorkerDeploymentAvailable, worker, consts.LabelRayWorker)
+		if err != nil {
+			return err
+		}
+		if active {
 			activeCounter++
-			createOrUpdateCondition(status, rayv1.RayWorkerDeploymentAvailable,
-				corev1.ConditionTrue)
-		} else {
-			createOrUpdateCondition(status, rayv1.RayWorkerDeploymentAvailable,
-				corev1.ConditionFalse)
-			// If the deployment is not active but all the pods owned by the deployment is pending or running, we mark the deployment running.
-			// This is a workaround to avoid http://jira.caicloud.xyz/browse/CLV-545.
-			if allPodsArePendingOrRunning(pods) {
-				running++
-			}
+		} else if pending {
+			running++
 		}
-	} else {
-		// If the available condition is not found, we mark the deployment running.
-		running++
+		syncDeploymentConditions(&groupStatus.Conditions, worker.Status.Conditions, consts.LabelRayWorker)
+		groupStatuses = append(groupStatuses, groupStatus)
 	}
-	syncDeploymentConditions(status, worker.Status.Conditions, consts.LabelRayWorker)
+	status.WorkerGroups = groupStatuses
+	setScaleStatus(ray)
+	// The worker conditions are reported by the worker groups.
+	removeConditions(&status.Conditions, rayv1.RayWorkerDeploymentAvailable,
+		rayv1.RayWorkerDeploymentProgressing, rayv1.RayWorkerDeploymentReplicaFailure)
 
-	// Get the pods belong to the deployment.
-	pods = &corev1.PodList{}
-	if err := r.List(context.TODO(), pods, client.MatchingLabels(map[string]string{
-		consts.LabelRayHead: head.Name,
-	})); err != nil {
+	// Set the head status.
+	headActive, headPending, err := r.syncHeadStatus(status, head)
+	if err != nil {
 		return err
 	}
-	if hasDeploymentAvailable(head) {
-		// Check if the deployment is available.
-		if isDeploymentAvailable(head) {
-			// If the deployment is active, we set the condition to serving status
-			// and mark it active.
-			activeCounter++
-			createOrUpdateCondition(status, rayv1.RayHeadDeploymentAvailable,
-				corev1.ConditionTrue)
-		} else {
-			createOrUpdateCondition(status, rayv1.RayHeadDeploymentAvailable,
-				corev1.ConditionFalse)
-			// If the deployment is not active but all the pods owned by the deployment is pending or running, we mark the deployment running.
-			// This is a workaround to avoid http://jira.caicloud.xyz/browse/CLV-545.
-			if allPodsArePendingOrRunning(pods) {
-				running++
-			}
-		}
-	} else {
-		// If the available condition is not found, we mark the deployment running.
+	if headActive {
+		activeCounter++
+	} else if headPending {
 		running++
 	}
-	syncDeploymentConditions(status, head.Status.Conditions, consts.LabelRayHead)
 
 	// If all resources work well, set the healthy.
+	health := corev1.ConditionFalse
 	if shouldActive == activeCounter {
-		createOrUpdateCondition(status, rayv1.RayHealth,
-			corev1.ConditionTrue)
+		health = corev1.ConditionTrue
 	} else if shouldActive == activeCounter+running {
-		createOrUpdateCondition(status, rayv1.RayHealth,
-			corev1.ConditionUnknown)
+		health = corev1.ConditionUnknown
+	}
+	// The Ray processes of an available head may have crashed while its container is
+	// still running, so the head is unhealthy if it cannot be reached.
+	if !r.syncHeadReachable(ray, service, headActive) && health == corev1.ConditionTrue {
+		health = corev1.ConditionFalse
+	}
+	// The head of a running Ray recovers the cluster from the external Redis after it
+	// restarts, thus the Ray keeps its phase and the workers are kept meanwhile.
+	if r.syncGCSFaultTolerance(ray, headActive, headPending) {
+		createOrUpdateConditionWithReason(&status.Conditions, rayv1.RayHealth, corev1.ConditionUnknown,
+			consts.ReasonHeadRecovering, "The head is restarting, the workers wait for it to recover the cluster")
 	} else {
-		createOrUpdateCondition(status, rayv1.RayHealth,
-			corev1.ConditionFalse)
+		createOrUpdateCondition(&status.Conditions, rayv1.RayHealth, health)
+		status.Phase = nextPhase(status.Phase, health, headActive)
 	}
+	r.syncWorkersRegistered(ray, headActive)
+
+	status.Message = fmt.Sprintf("%d of %d components are available", activeCounter, shouldActive)
+	setServiceStatus(status, service)
+	setEndpointsStatus(status, service, ingress)
+	setSchedulingStatus(status, ray.Spec.Scheduling, podGroup)
+
+	metrics.RecordStatus(ray, statusReplicas(ray, workers))
 
 	// Update the found object and write the result back if there are any changes.
 	if !equality.Semantic.DeepEqual(status, old) {
@@ -133,81 +137,484 @@ func (r *RayReconciler) updateStatus(ray *rayv1.Ray,
 	return nil
 }
 
-// syncDeploymentConditions syncs deployment conditions to serving conditions.
-func syncDeploymentConditions(status *rayv1.RayStatus,
-	conditions []appsv1.DeploymentCondition,
-	label string) {
-	switch label {
-	case consts.LabelRayHead:
-		found := false
-		for _, condition := range conditions {
-			switch condition.Type {
-			case appsv1.DeploymentProgressing:
-				createOrUpdateConditionWithReason(status, rayv1.RayHeadDeploymentProgressing,
-					condition.Status, condition.Reason, condition.Message)
-			case appsv1.DeploymentReplicaFailure:
-				found = true
-				createOrUpdateConditionWithReason(status, rayv1.RayHeadDeploymentReplicaFailure,
-					condition.Status, condition.Reason, condition.Message)
+// setScaleStatus sets the status replicas and the label selector of the scale
+// subresource, which is mapped to spec.worker. They are not set if the workers are
+// described by spec.workerGroups.
+func setScaleStatus(ray *rayv1.Ray) {
+	status := &ray.Status
+	status.WorkerReplicas = 0
+	status.WorkerSelector = ""
+	if len(ray.Spec.WorkerGroups) != 0 {
+		return
+	}
+	for _, group := range status.WorkerGroups {
+		if group.Name == rayv1.DefaultWorkerGroupName {
+			status.WorkerReplicas = group.Replicas
+		}
+	}
+	status.WorkerSelector = labels.SelectorFromSet(
+		composer.GetWorkerPodLabels(ray.Name, rayv1.DefaultWorkerGroupName)).String()
+}
+
+// nextPhase returns the phase of the Ray according to the previous phase, the Health
+// condition and whether the head is available.
+func nextPhase(phase rayv1.RayPhase, health corev1.ConditionStatus, headActive bool) rayv1.RayPhase {
+	hasRun := phase == rayv1.RayRunning || phase == rayv1.RayDegraded
+	switch {
+	case health == corev1.ConditionTrue:
+		return rayv1.RayRunning
+	case hasRun || (health == corev1.ConditionFalse && headActive):
+		return rayv1.RayDegraded
+	case health == corev1.ConditionFalse:
+		return rayv1.RayFailed
+	default:
+		return rayv1.RayPending
+	}
+}
+
+// setServiceStatus sets the IP of the head service and the dashboard URL. The dashboard
+// URL is set only if the head service exposes the dashboard port, or a port named
+// dashboard by the composer.
+func setServiceStatus(status *rayv1.RayStatus, service *corev1.Service) {
+	status.HeadServiceIP = ""
+	if service.Spec.ClusterIP != corev1.ClusterIPNone {
+		status.HeadServiceIP = service.Spec.ClusterIP
+	}
+	status.DashboardURL = ""
+	for _, p := range service.Spec.Ports {
+		if p.Port == consts.DefaultDashboardPort || p.Name == consts.PortNameDashboard {
+			status.DashboardURL = fmt.Sprintf("http://%s.%s.svc:%d", service.Name,
+				service.Namespace, p.Port)
+		}
+	}
+}
+
+// setSchedulingStatus sets the minimum members and the phase of the PodGroup, the status
+// is removed if the gang scheduling is disabled.
+func setSchedulingStatus(status *rayv1.RayStatus, spec *rayv1.SchedulingSpec,
+	podGroup *unstructured.Unstructured) {
+	if spec == nil || podGroup == nil {
+		status.Scheduling = nil
+		return
+	}
+	minMember, _, _ := unstructured.NestedInt64(podGroup.Object, "spec", "minMember")
+	phase, _, _ := unstructured.NestedString(podGroup.Object, "status", "phase")
+	status.Scheduling = &rayv1.SchedulingStatus{
+		Provider:     spec.Provider,
+		PodGroupName: podGroup.GetName(),
+		MinMember:    int32(minMember),
+		Phase:        phase,
+	}
+}
+
+// syncGCSFaultTolerance sets the GCSFaultToleranceActive condition. It returns true if
+// the GCS fault tolerance is enabled and the head of a running Ray is restarting, an
+// event is recorded when the restart is detected.
+func (r *RayReconciler) syncGCSFaultTolerance(ray *rayv1.Ray, headActive, headPending bool) bool {
+	status := &ray.Status
+	spec := ray.Spec.HeadFaultTolerance
+	if spec == nil {
+		if containConditionType(status.Conditions, rayv1.RayGCSFaultToleranceActive) {
+			createOrUpdateConditionWithReason(&status.Conditions, rayv1.RayGCSFaultToleranceActive,
+				corev1.ConditionFalse, consts.ReasonDisabled, "")
+		}
+		return false
+	}
+	createOrUpdateConditionWithReason(&status.Conditions, rayv1.RayGCSFaultToleranceActive,
+		corev1.ConditionTrue, consts.ReasonExternalRedis,
+		fmt.Sprintf("The GCS persists the metadata to %s", spec.RedisAddress))
+
+	hasRun := status.Phase == rayv1.RayRunning || status.Phase == rayv1.RayDegraded
+	if headActive || !headPending || !hasRun {
+		return false
+	}
+	recovering := false
+	for _, c := range status.Conditions {
+		if c.Type == rayv1.RayHealth && c.Reason == consts.ReasonHeadRecovering {
+			recovering = true
+		}
+	}
+	if !recovering {
+		r.Event(ray, consts.EventNormal, consts.ReasonHeadRecovering,
+			"The head is restarting, the workers are kept until it recovers the cluster from the external Redis")
+	}
+	return true
+}
+
+// syncHeadReachable probes the head if it is active and sets the RayHeadReachable
+// condition. It returns false only if the head is active but cannot be reached.
+func (r *RayReconciler) syncHeadReachable(ray *rayv1.Ray, service *corev1.Service, headActive bool) bool {
+	if r.Prober == nil {
+		return true
+	}
+	conditions := &ray.Status.Conditions
+	if !headActive {
+		setConditionUnknown(conditions, rayv1.RayHeadReachable)
+		return true
+	}
+	if err := r.Prober.ProbeHead(ray, service); err != nil {
+		r.Log.V(1).Info("The head is unreachable", "namespace", ray.Namespace,
+			"name", ray.Name, "error", err.Error())
+		createOrUpdateConditionWithReason(conditions, rayv1.RayHeadReachable,
+			corev1.ConditionFalse, consts.ReasonHeadUnreachable, err.Error())
+		return false
+	}
+	createOrUpdateConditionWithReason(conditions, rayv1.RayHeadReachable,
+		corev1.ConditionTrue, consts.ReasonHeadReachable, "")
+	return true
+}
+
+// syncWorkersRegistered gets the nodes registered with the head and sets the cluster
+// resources and the WorkersRegistered condition. A worker pod may be ready without
+// joining the cluster, e.g. when it fails to resolve the head service.
+func (r *RayReconciler) syncWorkersRegistered(ray *rayv1.Ray, headActive bool) {
+	if r.NodeSource == nil {
+		return
+	}
+	status := &ray.Status
+	if !composer.SupportsDashboard(ray.Spec.RayVersion) {
+		// The nodes cannot be got without the dashboard.
+		removeConditions(&status.Conditions, rayv1.RayWorkersRegistered)
+		status.ClusterResources = nil
+		return
+	}
+	if !headActive {
+		setConditionUnknown(&status.Conditions, rayv1.RayWorkersRegistered)
+		return
+	}
+	nodes, err := r.NodeSource.GetNodes(ray)
+	if err != nil {
+		r.Log.V(1).Info("Failed to get the nodes from the head", "namespace", ray.Namespace,
+			"name", ray.Name, "error", err.Error())
+		createOrUpdateConditionWithReason(&status.Conditions, rayv1.RayWorkersRegistered,
+			corev1.ConditionUnknown, consts.ReasonHeadUnreachable, err.Error())
+		return
+	}
+	status.ClusterResources = nodes
+
+	ready := int32(0)
+	for _, group := range status.WorkerGroups {
+		ready += group.ReadyReplicas
+	}
+	registered, reason := corev1.ConditionTrue, consts.ReasonWorkersJoined
+	if nodes.AliveWorkers != ready {
+		registered, reason = corev1.ConditionFalse, consts.ReasonWorkersMissing
+	}
+	createOrUpdateConditionWithReason(&status.Conditions, rayv1.RayWorkersRegistered, registered,
+		reason, fmt.Sprintf("%d of %d ready workers are registered", nodes.AliveWorkers, ready))
+}
+
+// setEndpointsStatus sets the endpoints which the ports of the head are exposed at by
+// the head service and the Ingress.
+func setEndpointsStatus(status *rayv1.RayStatus, service *corev1.Service,
+	ingress *networkingv1beta1.Ingress) {
+	var endpoints []rayv1.EndpointStatus
+	for _, p := range service.Spec.Ports {
+		endpoints = append(endpoints, rayv1.EndpointStatus{
+			Name: p.Name,
+			Type: rayv1.EndpointClusterIP,
+			Host: fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace),
+			Port: p.Port,
+		})
+		if p.NodePort != 0 {
+			endpoints = append(endpoints, rayv1.EndpointStatus{
+				Name: p.Name,
+				Type: rayv1.EndpointNodePort,
+				Port: p.NodePort,
+			})
+		}
+		for _, lb := range service.Status.LoadBalancer.Ingress {
+			host := lb.IP
+			if host == "" {
+				host = lb.Hostname
 			}
+			endpoints = append(endpoints, rayv1.EndpointStatus{
+				Name: p.Name,
+				Type: rayv1.EndpointLoadBalancer,
+				Host: host,
+				Port: p.Port,
+			})
 		}
-		if !found {
-			setConditionUnknown(status, rayv1.RayHeadDeploymentReplicaFailure)
+	}
+	if ingress != nil {
+		tls := map[string]bool{}
+		for _, t := range ingress.Spec.TLS {
+			for _, h := range t.Hosts {
+				tls[h] = true
+			}
 		}
-	case consts.LabelRayWorker:
-		found := false
-		for _, condition := range conditions {
-			switch condition.Type {
-			case appsv1.DeploymentProgressing:
-				createOrUpdateConditionWithReason(status, rayv1.RayWorkerDeploymentProgressing,
-					condition.Status, condition.Reason, condition.Message)
-			case appsv1.DeploymentReplicaFailure:
-				found = true
-				createOrUpdateConditionWithReason(status, rayv1.RayWorkerDeploymentReplicaFailure,
-					condition.Status, condition.Reason, condition.Message)
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			port := int32(80)
+			if tls[rule.Host] {
+				port = 443
+			}
+			for _, path := range rule.HTTP.Paths {
+				endpoints = append(endpoints, rayv1.EndpointStatus{
+					Name: servicePortName(service, path.Backend.ServicePort.IntValue()),
+					Type: rayv1.EndpointIngress,
+					Host: rule.Host,
+					Port: port,
+				})
 			}
 		}
-		if !found {
-			setConditionUnknown(status, rayv1.RayWorkerDeploymentReplicaFailure)
+	}
+	status.Endpoints = endpoints
+}
+
+// servicePortName returns the name of the port of the service.
+func servicePortName(service *corev1.Service, port int) string {
+	for _, p := range service.Spec.Ports {
+		if int(p.Port) == port {
+			return p.Name
+		}
+	}
+	return strconv.Itoa(port)
+}
+
+// syncHeadStatus sets the head status according to the workload of the head. It
+// returns whether the head is active, or is not active but pending or running.
+func (r *RayReconciler) syncHeadStatus(status *rayv1.RayStatus, head runtime.Object) (bool, bool, error) {
+	switch h := head.(type) {
+	case *appsv1.Deployment:
+		removeConditions(&status.Conditions, rayv1.RayHeadAvailable)
+		setReplicaStatus(&status.Head, h)
+		active, pending, err := r.syncDeploymentAvailable(&status.Conditions,
+			rayv1.RayHeadDeploymentAvailable, h, consts.LabelRayHead)
+		if err != nil {
+			return false, false, err
+		}
+		syncDeploymentConditions(&status.Conditions, h.Status.Conditions, consts.LabelRayHead)
+		return active, pending, nil
+	case *appsv1.StatefulSet:
+		removeConditions(&status.Conditions, rayv1.RayHeadDeploymentAvailable,
+			rayv1.RayHeadDeploymentProgressing, rayv1.RayHeadDeploymentReplicaFailure)
+		setStatefulSetReplicaStatus(&status.Head, h)
+		if isStatefulSetAvailable(h) {
+			createOrUpdateCondition(&status.Conditions, rayv1.RayHeadAvailable, corev1.ConditionTrue)
+			return true, false, nil
+		}
+		createOrUpdateCondition(&status.Conditions, rayv1.RayHeadAvailable, corev1.ConditionFalse)
+		pods := &corev1.PodList{}
+		if err := r.List(context.TODO(), pods, client.InNamespace(h.Namespace),
+			client.MatchingLabels(map[string]string{
+				consts.LabelRayHead: h.Name,
+			})); err != nil {
+			return false, false, err
+		}
+		return false, allPodsArePendingOrRunning(pods), nil
+	case *corev1.Pod:
+		removeConditions(&status.Conditions, rayv1.RayHeadDeploymentAvailable,
+			rayv1.RayHeadDeploymentProgressing, rayv1.RayHeadDeploymentReplicaFailure)
+		setPodReplicaStatus(&status.Head, h)
+		if isPodReady(h) {
+			createOrUpdateConditionWithReason(&status.Conditions, rayv1.RayHeadAvailable,
+				corev1.ConditionTrue, string(h.Status.Phase), h.Status.Message)
+			return true, false, nil
+		}
+		createOrUpdateConditionWithReason(&status.Conditions, rayv1.RayHeadAvailable,
+			corev1.ConditionFalse, string(h.Status.Phase), h.Status.Message)
+		return false, h.DeletionTimestamp == nil &&
+			(h.Status.Phase == "" || h.Status.Phase == corev1.PodPending ||
+				h.Status.Phase == corev1.PodRunning), nil
+	}
+	return false, false, fmt.Errorf("unexpected kind of the head: %T", head)
+}
+
+// syncDeploymentAvailable sets the available condition according to the deployment.
+// It returns whether the deployment is active, or is not active but all of its pods
+// are pending or running.
+func (r *RayReconciler) syncDeploymentAvailable(conditions *[]rayv1.RayCondition,
+	conditionType rayv1.RayConditionType,
+	deploy *appsv1.Deployment, label string) (bool, bool, error) {
+	// Get the pods belong to the deployment.
+	pods := &corev1.PodList{}
+	if err := r.List(context.TODO(), pods, client.InNamespace(deploy.Namespace),
+		client.MatchingLabels(map[string]string{
+			label: deploy.Name,
+		})); err != nil {
+		return false, false, err
+	}
+
+	if !hasDeploymentAvailable(deploy) {
+		// If the available condition is not found, we mark the deployment running.
+		return false, true, nil
+	}
+	// Check if the deployment is available.
+	if isDeploymentAvailable(deploy) {
+		// If the deployment is active, we set the condition to serving status
+		// and mark it active.
+		createOrUpdateCondition(conditions, conditionType, corev1.ConditionTrue)
+		return true, false, nil
+	}
+	createOrUpdateCondition(conditions, conditionType, corev1.ConditionFalse)
+	// If the deployment is not active but all the pods owned by the deployment is pending or running, we mark the deployment running.
+	// This is a workaround to avoid http://jira.caicloud.xyz/browse/CLV-545.
+	return false, allPodsArePendingOrRunning(pods), nil
+}
+
+func setReplicaStatus(status *rayv1.ReplicaStatus, deploy *appsv1.Deployment) {
+	status.Replicas = deploy.Status.Replicas
+	status.ReadyReplicas = deploy.Status.ReadyReplicas
+	status.AvailableReplicas = deploy.Status.AvailableReplicas
+	status.UnavailableReplicas = deploy.Status.UnavailableReplicas
+	status.UpdatedReplicas = deploy.Status.UpdatedReplicas
+}
+
+// statusReplicas returns the desired and the observed replicas of the head and the
+// worker groups, which are exported as metrics.
+func statusReplicas(ray *rayv1.Ray, workers []*appsv1.Deployment) []metrics.Replicas {
+	headReplicas := int32(1)
+	if ray.Spec.Head.Replicas != nil {
+		headReplicas = *ray.Spec.Head.Replicas
+	}
+	replicas := []metrics.Replicas{
+		{
+			Component: metrics.ComponentHead,
+			Desired:   headReplicas,
+			Ready:     ray.Status.Head.ReadyReplicas,
+			Available: ray.Status.Head.AvailableReplicas,
+		},
+	}
+	for i, group := range ray.Status.WorkerGroups {
+		desired := int32(1)
+		if workers[i].Spec.Replicas != nil {
+			desired = *workers[i].Spec.Replicas
+		}
+		replicas = append(replicas, metrics.Replicas{
+			Component: metrics.ComponentWorker,
+			Group:     group.Name,
+			Desired:   desired,
+			Ready:     group.ReadyReplicas,
+			Available: group.AvailableReplicas,
+		})
+	}
+	return replicas
+}
+
+func setStatefulSetReplicaStatus(status *rayv1.ReplicaStatus, sts *appsv1.StatefulSet) {
+	status.Replicas = sts.Status.Replicas
+	status.ReadyReplicas = sts.Status.ReadyReplicas
+	status.AvailableReplicas = sts.Status.ReadyReplicas
+	status.UnavailableReplicas = sts.Status.Replicas - sts.Status.ReadyReplicas
+	status.UpdatedReplicas = sts.Status.UpdatedReplicas
+}
+
+func setPodReplicaStatus(status *rayv1.ReplicaStatus, pod *corev1.Pod) {
+	*status = rayv1.ReplicaStatus{
+		Replicas:            1,
+		UnavailableReplicas: 1,
+	}
+	if pod.DeletionTimestamp == nil {
+		status.UpdatedReplicas = 1
+	}
+	if isPodReady(pod) {
+		status.ReadyReplicas = 1
+		status.AvailableReplicas = 1
+		status.UnavailableReplicas = 0
+	}
+}
+
+// updateReconcileErrorStatus records the terminal error in the ReconcileError condition,
+// and the validation error in the ValidationFailed condition.
+func (r *RayReconciler) updateReconcileErrorStatus(ray *rayv1.Ray, terr *terminalError) error {
+	old := ray.Status.DeepCopy()
+	status := &ray.Status
+
+	now := metav1.Now()
+	if status.StartTime == nil {
+		status.StartTime = &now
+	}
+	status.LastReconcileTime = &now
+	if ray.Generation > status.ObservedGeneration {
+		status.ObservedGeneration = ray.Generation
+	}
+	if terr.reason == consts.ReasonValidationFailed {
+		createOrUpdateConditionWithReason(&status.Conditions, rayv1.RayValidationFailed,
+			corev1.ConditionTrue, consts.ReasonValidationFailed, terr.Error())
+	}
+	createOrUpdateConditionWithReason(&status.Conditions, rayv1.RayReconcileError,
+		corev1.ConditionTrue, terr.reason, terr.Error())
+	status.Phase = rayv1.RayFailed
+	status.Message = terr.Error()
+
+	if !equality.Semantic.DeepEqual(status, old) {
+		r.Log.V(1).Info("Updating Ray status", "namespace", ray.Namespace,
+			"name", ray.Name,
+			"status", ray.Status)
+		if err := r.Status().Update(context.TODO(), ray); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
-func setConditionUnknown(status *rayv1.RayStatus,
+// syncDeploymentConditions syncs deployment conditions to ray conditions.
+func syncDeploymentConditions(conditions *[]rayv1.RayCondition,
+	deployConditions []appsv1.DeploymentCondition,
+	label string) {
+	progressing := rayv1.RayHeadDeploymentProgressing
+	replicaFailure := rayv1.RayHeadDeploymentReplicaFailure
+	if label == consts.LabelRayWorker {
+		progressing = rayv1.RayWorkerDeploymentProgressing
+		replicaFailure = rayv1.RayWorkerDeploymentReplicaFailure
+	}
+
+	found := false
+	for _, condition := range deployConditions {
+		switch condition.Type {
+		case appsv1.DeploymentProgressing:
+			createOrUpdateConditionWithReason(conditions, progressing,
+				condition.Status, condition.Reason, condition.Message)
+		case appsv1.DeploymentReplicaFailure:
+			found = true
+			createOrUpdateConditionWithReason(conditions, replicaFailure,
+				condition.Status, condition.Reason, condition.Message)
+		}
+	}
+	if !found {
+		setConditionUnknown(conditions, replicaFailure)
+	}
+}
+
+func setConditionUnknown(conditions *[]rayv1.RayCondition,
 	conditionType rayv1.RayConditionType) {
-	createOrUpdateCondition(status, conditionType, corev1.ConditionUnknown)
+	createOrUpdateCondition(conditions, conditionType, corev1.ConditionUnknown)
 }
 
-func createOrUpdateConditionWithReason(status *rayv1.RayStatus,
+func createOrUpdateConditionWithReason(conditions *[]rayv1.RayCondition,
 	conditionType rayv1.RayConditionType,
 	boolVal corev1.ConditionStatus, reason, msg string) {
-	if !containConditionType(status, conditionType) {
-		status.Conditions = append(status.Conditions, newCondition(conditionType,
+	if !containConditionType(*conditions, conditionType) {
+		*conditions = append(*conditions, newCondition(conditionType,
 			boolVal, reason, msg))
 	} else {
-		for i := range status.Conditions {
-			if status.Conditions[i].Type == conditionType {
-				if status.Conditions[i].Status != boolVal {
-					status.Conditions[i].LastTransitionTime = metav1.Now()
+		for i := range *conditions {
+			c := &(*conditions)[i]
+			if c.Type == conditionType {
+				if c.Status != boolVal {
+					c.LastTransitionTime = metav1.Now()
 				}
-				status.Conditions[i].Status = boolVal
-				status.Conditions[i].LastUpdateTime = metav1.Now()
-				status.Conditions[i].Reason = reason
-				status.Conditions[i].Message = msg
+				c.Status = boolVal
+				c.LastUpdateTime = metav1.Now()
+				c.Reason = reason
+				c.Message = msg
 			}
 		}
 	}
 }
 
-func createOrUpdateCondition(status *rayv1.RayStatus,
+func createOrUpdateCondition(conditions *[]rayv1.RayCondition,
 	conditionType rayv1.RayConditionType,
 	boolVal corev1.ConditionStatus) {
-	createOrUpdateConditionWithReason(status, conditionType, boolVal, "", "")
+	createOrUpdateConditionWithReason(conditions, conditionType, boolVal, "", "")
 }
 
-func containConditionType(status *rayv1.RayStatus,
+func containConditionType(conditions []rayv1.RayCondition,
 	conditionType rayv1.RayConditionType) bool {
-	for _, condition := range status.Conditions {
+	for _, condition := range conditions {
 		if condition.Type == conditionType {
 			return true
 		}
@@ -215,6 +622,23 @@ func containConditionType(status *rayv1.RayStatus,
 	return false
 }
 
+func removeConditions(conditions *[]rayv1.RayCondition,
+	conditionTypes ...rayv1.RayConditionType) {
+	kept := (*conditions)[:0]
+	for _, condition := range *conditions {
+		remove := false
+		for _, t := range conditionTypes {
+			if condition.Type == t {
+				remove = true
+			}
+		}
+		if !remove {
+			kept = append(kept, condition)
+		}
+	}
+	*conditions = kept
+}
+
 func newCondition(conditionType rayv1.RayConditionType,
 	boolVal corev1.ConditionStatus,
 	reason, message string) rayv1.RayCondition {
@@ -247,6 +671,30 @@ func hasDeploymentAvailable(deploy *appsv1.Deployment) bool {
 	return false
 }
 
+// isStatefulSetAvailable returns true if the latest revision of the statefulset is
+// observed and all of its replicas are ready.
+func isStatefulSetAvailable(sts *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	return sts.Status.ObservedGeneration >= sts.Generation &&
+		sts.Status.ReadyReplicas >= replicas
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady &&
+			condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
 func allPodsArePendingOrRunning(pods *corev1.PodList) bool {
 	for _, p := range pods.Items {
 		if p.Status.Phase != corev1.PodPending && p.Status.Phase != corev1.PodRunning {
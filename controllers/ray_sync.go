@@ -1,67 +1,156 @@
 package controllers
 
 import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/autoscaler"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+	"github.com/kubeflow/ray-operator/pkg/prober"
 )
 
-// sync handles all requests.
+// sync handles all requests. A terminalError is returned if the Ray cannot be
+// reconciled until its specification is changed, the other errors are transient.
 func (r *RayReconciler) sync(ray *rayv1.Ray) (ctrl.Result, error) {
 	r.Log.V(1).Info("Sync the object Ray", "namespace", ray.Namespace, "instance", ray.Name)
 	defer r.Log.V(1).Info("Finished syncing Ray", "namespace", ray.Namespace, "instance", ray.Name)
 
-	// TODO(gaocegege): We should do the validation in Validation Webhook.
+	if ray.DeletionTimestamp != nil {
+		return r.finalize(ray)
+	}
+
+	// The finalizer is synced before the Ray is defaulted, since the Ray is updated
+	// to add or remove it.
+	if err := r.syncFinalizer(ray); err != nil {
+		return ctrl.Result{}, err
+	}
+	// The mutating webhook may be disabled, thus the defaults are applied again here. They
+	// are not persisted, since only the status is updated by the controller.
+	ray.Default()
+	// The validating webhook rejects invalid specifications, but it may be disabled,
+	// thus we validate them again here.
 	if err := r.Validator.ValidateRay(ray); err != nil {
-		// Do not requeue the requests since we cannot deal with it.
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.terminate(ray, newTerminalError(consts.ReasonValidationFailed, err))
 	}
 
 	desiredHeadService, err := r.Composer.DesiredHeadService(ray)
 	if err != nil {
-		// Do not requeue the requests since we cannot deal with it.
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.terminate(ray, newTerminalError(consts.ReasonComposeFailed, err))
 	}
-	// TODO(gaocegege): Update status according to the actualService.
-	if _, err := r.createOrUpdateService(ray, desiredHeadService); err != nil {
-		return ctrl.Result{
-			Requeue: true,
-		}, nil
+	actualHeadService, err := r.createOrUpdateService(ray, desiredHeadService)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
-
-	desiredHead, err := r.Composer.DesiredHead(ray)
+	desiredWorkerService, err := r.Composer.DesiredWorkerService(ray)
 	if err != nil {
-		// Do not requeue the requests since we cannot deal with it.
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.terminate(ray, newTerminalError(consts.ReasonComposeFailed, err))
+	}
+	if _, err := r.createOrUpdateService(ray, desiredWorkerService); err != nil {
+		return ctrl.Result{}, err
+	}
+	actualHeadIngress, err := r.syncHeadIngress(ray)
+	if terr, ok := err.(*terminalError); ok {
+		return ctrl.Result{}, r.terminate(ray, terr)
+	} else if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	actualHead, err := r.createOrUpdateDeployment(ray, desiredHead)
-	if err != nil {
-		return ctrl.Result{
-			Requeue: true,
-		}, nil
+	// The PodGroup is created before the pods, which are not scheduled until it exists.
+	actualPodGroup, err := r.syncPodGroup(ray)
+	if terr, ok := err.(*terminalError); ok {
+		return ctrl.Result{}, r.terminate(ray, terr)
+	} else if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	desiredWorker, err := r.Composer.DesiredWorker(ray)
-	if err != nil {
-		// Do not requeue the requests since we cannot deal with it.
-		return ctrl.Result{}, nil
+	// The head runs in a Deployment, a StatefulSet or a Pod according to the workload kind.
+	actualHead, err := r.syncHead(ray)
+	if terr, ok := err.(*terminalError); ok {
+		return ctrl.Result{}, r.terminate(ray, terr)
+	} else if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	actualWorker, err := r.createOrUpdateDeployment(ray, desiredWorker)
+	// The autoscaler records the desired workers in the status, which is used by the composer.
+	if err := r.Autoscaler.Scale(ray); err != nil {
+		// Keep the current workers and retry in the next period.
+		r.Log.Error(err, "Failed to scale the workers", "namespace", ray.Namespace,
+			"instance", ray.Name)
+		r.Event(ray, consts.EventWarning, consts.ReasonScaleFailed,
+			fmt.Sprintf("Failed to scale the workers: %v", err))
+		createOrUpdateConditionWithReason(&ray.Status.Conditions, rayv1.RayAutoscalerReady,
+			corev1.ConditionFalse, consts.ReasonScaleFailed, err.Error())
+	} else if ray.Spec.Autoscaling != nil {
+		createOrUpdateCondition(&ray.Status.Conditions, rayv1.RayAutoscalerReady, corev1.ConditionTrue)
+	} else {
+		removeConditions(&ray.Status.Conditions, rayv1.RayAutoscalerReady)
+	}
+
+	desiredWorkers, err := r.Composer.DesiredWorkers(ray)
 	if err != nil {
-		return ctrl.Result{
-			Requeue: true,
-		}, nil
+		return ctrl.Result{}, r.terminate(ray, newTerminalError(consts.ReasonComposeFailed, err))
+	}
+
+	actualWorkers := make([]*appsv1.Deployment, 0, len(desiredWorkers))
+	for _, desiredWorker := range desiredWorkers {
+		actualWorker, err := r.createOrUpdateDeployment(ray, desiredWorker)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		actualWorkers = append(actualWorkers, actualWorker)
+	}
+
+	// Delete the worker deployments whose groups are removed from the specification.
+	if err := r.deleteStaleWorkerDeployments(ray, desiredWorkers); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	// Update Serving status according to the deployment, pvc and hpa.
-	if err := r.updateStatus(ray, actualHead, actualWorker); err != nil {
+	if err := r.updateStatus(ray, actualHeadService, actualHeadIngress, actualPodGroup,
+		actualHead, actualWorkers); err != nil {
 		r.Log.Error(err, "Failed to update the status for ray", "instance", ray.Name)
-		return reconcile.Result{
-			Requeue: true,
+		r.Event(ray, consts.EventWarning, consts.ReasonReconcileFailed,
+			fmt.Sprintf("Failed to update the status: %v", err))
+		return ctrl.Result{}, err
+	}
+	if ray.Spec.Autoscaling != nil {
+		// Check the demand periodically.
+		return ctrl.Result{
+			RequeueAfter: autoscaler.SyncPeriod,
+		}, nil
+	}
+	if r.Prober != nil {
+		// Probe the head periodically, since a crash of the Ray processes does not
+		// change any watched resource.
+		return ctrl.Result{
+			RequeueAfter: prober.SyncPeriod,
+		}, nil
+	}
+	if ray.Spec.Scheduling != nil {
+		// Report the phase of the PodGroup periodically.
+		return ctrl.Result{
+			RequeueAfter: podGroupPollPeriod,
 		}, nil
 	}
 	return ctrl.Result{}, nil
 }
+
+// terminate records the terminal error in the status and returns it. If the status
+// cannot be updated, the error of the update is returned to retry.
+func (r *RayReconciler) terminate(ray *rayv1.Ray, terr *terminalError) error {
+	// The validator records the event by itself.
+	if terr.reason != consts.ReasonValidationFailed {
+		r.Event(ray, consts.EventWarning, terr.reason, terr.Error())
+	}
+	if err := r.updateReconcileErrorStatus(ray, terr); err != nil {
+		r.Log.Error(err, "Failed to update the status for ray", "instance", ray.Name)
+		r.Event(ray, consts.EventWarning, consts.ReasonReconcileFailed,
+			fmt.Sprintf("Failed to update the status: %v", err))
+		return err
+	}
+	return terr
+}
@@ -0,0 +1,259 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/autoscaler"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// fakeDemandSource is an autoscaler.DemandSource which returns the configured results.
+type fakeDemandSource struct {
+	demand *autoscaler.Demand
+	err    error
+}
+
+func (s fakeDemandSource) GetDemand(ray *rayv1.Ray) (*autoscaler.Demand, error) {
+	return s.demand, s.err
+}
+
+func findCondition(conditions []rayv1.RayCondition, t rayv1.RayConditionType) *rayv1.RayCondition {
+	for i := range conditions {
+		if conditions[i].Type == t {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func TestSyncScaleFailed(t *testing.T) {
+	ray := newTestRay("test")
+	ray.Spec.Autoscaling = &rayv1.AutoscalingSpec{MaxReplicas: 10}
+	r := newTestReconciler(ray)
+	recorder := r.EventRecorder.(*record.FakeRecorder)
+	r.Autoscaler = autoscaler.New(r.EventRecorder, logf.NullLogger{},
+		fakeDemandSource{err: fmt.Errorf("connection refused")})
+
+	if _, err := r.sync(ray); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	condition := findCondition(ray.Status.Conditions, rayv1.RayAutoscalerReady)
+	if condition == nil || condition.Status != corev1.ConditionFalse ||
+		condition.Reason != consts.ReasonScaleFailed {
+		t.Errorf("expected the condition %s to be False, got %v", rayv1.RayAutoscalerReady, condition)
+	}
+	if !hasEvent(recorder, consts.EventWarning, consts.ReasonScaleFailed) {
+		t.Errorf("expected a warning event of %s", consts.ReasonScaleFailed)
+	}
+}
+
+// fakeNodeSource is a prober.NodeSource which returns the configured nodes.
+type fakeNodeSource struct {
+	nodes *rayv1.ClusterResourcesStatus
+}
+
+func (s fakeNodeSource) GetNodes(ray *rayv1.Ray) (*rayv1.ClusterResourcesStatus, error) {
+	return s.nodes, nil
+}
+
+func TestSyncWorkersRegistered(t *testing.T) {
+	cases := []struct {
+		name         string
+		aliveWorkers int32
+		status       corev1.ConditionStatus
+		reason       string
+	}{
+		{"all joined", 2, corev1.ConditionTrue, consts.ReasonWorkersJoined},
+		{"missing", 1, corev1.ConditionFalse, consts.ReasonWorkersMissing},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestRay("test")
+			ray.Status.WorkerGroups = []rayv1.WorkerGroupStatus{{
+				Name:          "default",
+				ReplicaStatus: rayv1.ReplicaStatus{ReadyReplicas: 2},
+			}}
+			r := newTestReconciler(ray)
+			r.NodeSource = fakeNodeSource{nodes: &rayv1.ClusterResourcesStatus{
+				AliveNodes:   c.aliveWorkers + 1,
+				AliveWorkers: c.aliveWorkers,
+			}}
+
+			r.syncWorkersRegistered(ray, true)
+			cond := findCondition(ray.Status.Conditions, rayv1.RayWorkersRegistered)
+			if cond == nil || cond.Status != c.status || cond.Reason != c.reason {
+				t.Errorf("expected WorkersRegistered %s with the reason %s, got %+v", c.status, c.reason, cond)
+			}
+		})
+	}
+}
+
+func TestNextPhase(t *testing.T) {
+	cases := []struct {
+		name       string
+		phase      rayv1.RayPhase
+		health     corev1.ConditionStatus
+		headActive bool
+		want       rayv1.RayPhase
+	}{
+		{"starting", "", corev1.ConditionUnknown, false, rayv1.RayPending},
+		{"healthy", rayv1.RayPending, corev1.ConditionTrue, true, rayv1.RayRunning},
+		{"recovered", rayv1.RayDegraded, corev1.ConditionTrue, true, rayv1.RayRunning},
+		{"running unhealthy", rayv1.RayRunning, corev1.ConditionFalse, true, rayv1.RayDegraded},
+		{"running head restarting", rayv1.RayRunning, corev1.ConditionUnknown, false, rayv1.RayDegraded},
+		{"degraded head failed", rayv1.RayDegraded, corev1.ConditionFalse, false, rayv1.RayDegraded},
+		{"workers failed before running", rayv1.RayPending, corev1.ConditionFalse, true, rayv1.RayDegraded},
+		{"head failed before running", rayv1.RayPending, corev1.ConditionFalse, false, rayv1.RayFailed},
+		{"failed head restarting", rayv1.RayFailed, corev1.ConditionUnknown, false, rayv1.RayPending},
+		{"failed recovered", rayv1.RayFailed, corev1.ConditionTrue, true, rayv1.RayRunning},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := nextPhase(c.phase, c.health, c.headActive); got != c.want {
+				t.Errorf("expected the phase %s, got %s", c.want, got)
+			}
+		})
+	}
+}
+
+func TestSyncPhase(t *testing.T) {
+	cases := []struct {
+		name    string
+		ray     *rayv1.Ray
+		objs    []runtime.Object
+		phase   rayv1.RayPhase
+		message string
+	}{
+		{
+			name:    "pending",
+			ray:     newTestRay("test"),
+			phase:   rayv1.RayPending,
+			message: "0 of 2 components are available",
+		},
+		{
+			name: "failed",
+			ray: func() *rayv1.Ray {
+				ray := newTestRay("test")
+				replicas := int32(2)
+				ray.Spec.Head.Replicas = &replicas
+				return ray
+			}(),
+			phase:   rayv1.RayFailed,
+			message: "spec.head.replicas",
+		},
+		{
+			name:    "terminating",
+			ray:     newTestTerminatingRay("test", time.Second),
+			objs:    []runtime.Object{newTestWorkerPod(newTestRay("test"), "test-worker-a")},
+			phase:   rayv1.RayTerminating,
+			message: "Waiting for 1 workers to drain",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := newTestReconciler(append(c.objs, c.ray)...)
+			r.Autoscaler = autoscaler.New(r.EventRecorder, logf.NullLogger{}, fakeDemandSource{})
+			_, _ = r.sync(c.ray)
+			if c.ray.Status.Phase != c.phase || !strings.Contains(c.ray.Status.Message, c.message) {
+				t.Errorf("expected the phase %s with the message %q, got %s with %q",
+					c.phase, c.message, c.ray.Status.Phase, c.ray.Status.Message)
+			}
+		})
+	}
+}
+
+func TestSetServiceStatus(t *testing.T) {
+	cases := []struct {
+		name      string
+		clusterIP string
+		ports     []corev1.ServicePort
+		ip        string
+		url       string
+	}{
+		{
+			name:      "default dashboard port",
+			clusterIP: "10.0.0.1",
+			ports:     []corev1.ServicePort{{Name: "gcs", Port: 6379}, {Name: "http-8265", Port: 8265}},
+			ip:        "10.0.0.1",
+			url:       "http://test-head.default.svc:8265",
+		},
+		{
+			name:      "named dashboard port",
+			clusterIP: "10.0.0.1",
+			ports:     []corev1.ServicePort{{Name: consts.PortNameDashboard, Port: 8266}},
+			ip:        "10.0.0.1",
+			url:       "http://test-head.default.svc:8266",
+		},
+		{
+			name:      "headless without the dashboard",
+			clusterIP: corev1.ClusterIPNone,
+			ports:     []corev1.ServicePort{{Name: "gcs", Port: 6379}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-head", Namespace: "default"},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: c.clusterIP,
+					Ports:     c.ports,
+				},
+			}
+			status := &rayv1.RayStatus{
+				HeadServiceIP: "10.0.0.2",
+				DashboardURL:  "http://stale",
+			}
+			setServiceStatus(status, service)
+			if status.HeadServiceIP != c.ip || status.DashboardURL != c.url {
+				t.Errorf("expected the IP %q and the dashboard URL %q, got %q and %q",
+					c.ip, c.url, status.HeadServiceIP, status.DashboardURL)
+			}
+		})
+	}
+}
+
+func TestSetScaleStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		groups   []rayv1.WorkerGroupSpec
+		replicas int32
+		selector string
+	}{
+		{
+			name:     "worker",
+			replicas: 3,
+			selector: "ray=test,ray-worker=test-worker",
+		},
+		{
+			name:   "worker groups",
+			groups: []rayv1.WorkerGroupSpec{{Name: rayv1.DefaultWorkerGroupName}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestRay("test")
+			ray.Spec.WorkerGroups = c.groups
+			ray.Status.WorkerReplicas = 1
+			ray.Status.WorkerSelector = "stale"
+			ray.Status.WorkerGroups = []rayv1.WorkerGroupStatus{{
+				Name:          rayv1.DefaultWorkerGroupName,
+				ReplicaStatus: rayv1.ReplicaStatus{Replicas: 3},
+			}}
+			setScaleStatus(ray)
+			if ray.Status.WorkerReplicas != c.replicas || ray.Status.WorkerSelector != c.selector {
+				t.Errorf("expected %d replicas with the selector %q, got %d with %q", c.replicas,
+					c.selector, ray.Status.WorkerReplicas, ray.Status.WorkerSelector)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+const (
+	// FinalizerGracefulTermination is the finalizer added to the Ray when the
+	// graceful termination is enabled.
+	FinalizerGracefulTermination = "ray.kubeflow.org/graceful-termination"
+
+	// terminationPollPeriod is the period to check the progress of the termination.
+	terminationPollPeriod = 5 * time.Second
+)
+
+// syncFinalizer adds the finalizer to the Ray if the graceful termination is enabled,
+// and removes it if the graceful termination is disabled. The Ray is updated, thus it
+// must not be defaulted by the controller, otherwise the defaults are persisted.
+func (r *RayReconciler) syncFinalizer(ray *rayv1.Ray) error {
+	has := containsString(ray.Finalizers, FinalizerGracefulTermination)
+	switch {
+	case ray.Spec.Termination != nil && !has:
+		ray.Finalizers = append(ray.Finalizers, FinalizerGracefulTermination)
+	case ray.Spec.Termination == nil && has:
+		ray.Finalizers = removeString(ray.Finalizers, FinalizerGracefulTermination)
+	default:
+		return nil
+	}
+	r.Log.V(1).Info("Updating Ray finalizers", "namespace", ray.Namespace, "name", ray.Name,
+		"finalizers", ray.Finalizers)
+	if err := r.Update(context.TODO(), ray); err != nil {
+		r.Log.Error(err, "Failed to update the finalizers of the ray")
+		r.Event(ray, consts.EventWarning, consts.ReasonUpdate,
+			fmt.Sprintf("Failed to update the finalizers: %v", err))
+		return err
+	}
+	return nil
+}
+
+// finalize terminates the Ray gracefully in the following steps:
+//  1. Scale the workers to zero and wait up to the grace period for them to drain.
+//  2. Run the pre-delete hook if it is specified and wait for it to finish.
+//  3. Delete the head and release the finalizer.
+//
+// The other resources are deleted by the garbage collector after the finalizer is released.
+func (r *RayReconciler) finalize(ray *rayv1.Ray) (ctrl.Result, error) {
+	if !containsString(ray.Finalizers, FinalizerGracefulTermination) {
+		return ctrl.Result{}, nil
+	}
+	r.Log.V(1).Info("Terminating Ray", "namespace", ray.Namespace, "instance", ray.Name)
+
+	old := ray.Status.DeepCopy()
+	ray.Status.Phase = rayv1.RayTerminating
+
+	remaining, err := r.drainWorkers(ray)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	gracePeriod := time.Duration(rayv1.DefaultTerminationGracePeriodSeconds) * time.Second
+	if ray.Spec.Termination != nil && ray.Spec.Termination.GracePeriodSeconds != nil {
+		gracePeriod = time.Duration(*ray.Spec.Termination.GracePeriodSeconds) * time.Second
+	}
+	if wait := ray.DeletionTimestamp.Add(gracePeriod).Sub(time.Now()); remaining > 0 && wait > 0 {
+		ray.Status.Message = fmt.Sprintf("Waiting for %d workers to drain", remaining)
+		if wait > terminationPollPeriod {
+			wait = terminationPollPeriod
+		}
+		return ctrl.Result{RequeueAfter: wait}, r.updateTerminatingStatus(ray, old)
+	}
+
+	if ray.Spec.Termination != nil && ray.Spec.Termination.PreDeleteHook != nil {
+		hook, err := r.createPreDeleteHookIfNotExists(ray)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		switch {
+		case isJobConditionTrue(hook, batchv1.JobComplete):
+		case isJobConditionTrue(hook, batchv1.JobFailed):
+			message := fmt.Sprintf("The pre-delete hook %s failed, deleting the head anyway", hook.Name)
+			if ray.Status.Message != message {
+				r.Event(ray, consts.EventWarning, consts.ReasonDelete, message)
+			}
+			ray.Status.Message = message
+		default:
+			ray.Status.Message = fmt.Sprintf("Waiting for the pre-delete hook %s to finish", hook.Name)
+			// The Ray is synced again when the status of the Job changes.
+			return ctrl.Result{}, r.updateTerminatingStatus(ray, old)
+		}
+	}
+
+	// No kind of the head is desired, thus all of them are deleted.
+	if err := r.deleteStaleHeads(ray, ""); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateTerminatingStatus(ray, old); err != nil {
+		return ctrl.Result{}, err
+	}
+	ray.Finalizers = removeString(ray.Finalizers, FinalizerGracefulTermination)
+	if err := r.Update(context.TODO(), ray); err != nil {
+		r.Log.Error(err, "Failed to release the finalizer of the ray")
+		r.Event(ray, consts.EventWarning, consts.ReasonDelete,
+			fmt.Sprintf("Failed to release the finalizer: %v", err))
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// drainWorkers scales all worker deployments of the Ray to zero, and returns the
+// number of the worker pods which are not terminated yet.
+func (r *RayReconciler) drainWorkers(ray *rayv1.Ray) (int, error) {
+	deploys := &appsv1.DeploymentList{}
+	if err := r.List(context.TODO(), deploys, client.InNamespace(ray.Namespace),
+		client.MatchingLabels(map[string]string{consts.LabelRay: ray.Name})); err != nil {
+		r.Log.Error(err, "Failed to list the deployments")
+		r.Event(ray, consts.EventWarning, consts.ReasonScaleDown,
+			fmt.Sprintf("Failed to list the deployments: %v", err))
+		return 0, err
+	}
+	for i := range deploys.Items {
+		deploy := &deploys.Items[i]
+		if _, ok := deploy.Spec.Template.Labels[consts.LabelRayWorker]; !ok ||
+			!metav1.IsControlledBy(deploy, ray) {
+			continue
+		}
+		if deploy.Spec.Replicas != nil && *deploy.Spec.Replicas == 0 {
+			continue
+		}
+		zero := int32(0)
+		deploy.Spec.Replicas = &zero
+		r.Log.V(1).Info("Scaling Deployment to zero", "namespace", deploy.Namespace, "name", deploy.Name)
+		if err := r.Update(context.TODO(), deploy); err != nil {
+			r.Log.Error(err, "Failed to scale the deployment")
+			r.Event(ray, consts.EventWarning, consts.ReasonScaleDown,
+				fmt.Sprintf("Failed to scale the deployment %s to zero: %v", deploy.Name, err))
+			return 0, err
+		}
+		r.Event(ray, consts.EventNormal, consts.ReasonScaleDown,
+			fmt.Sprintf("Scale the deployment %s to zero to drain the workers", deploy.Name))
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(context.TODO(), pods, client.InNamespace(ray.Namespace),
+		client.MatchingLabels(map[string]string{
+			consts.LabelRay: ray.Name,
+		})); err != nil {
+		r.Log.Error(err, "Failed to list the pods")
+		r.Event(ray, consts.EventWarning, consts.ReasonScaleDown,
+			fmt.Sprintf("Failed to list the pods: %v", err))
+		return 0, err
+	}
+	remaining := 0
+	for _, pod := range pods.Items {
+		if _, ok := pod.Labels[consts.LabelRayWorker]; ok {
+			remaining++
+		}
+	}
+	return remaining, nil
+}
+
+// createPreDeleteHookIfNotExists creates the pre-delete hook. The hook is not updated
+// since it must not be run twice.
+func (r *RayReconciler) createPreDeleteHookIfNotExists(ray *rayv1.Ray) (*batchv1.Job, error) {
+	hook, err := r.Composer.DesiredPreDeleteHook(ray)
+	if err != nil {
+		r.Event(ray, consts.EventWarning, consts.ReasonComposeFailed, err.Error())
+		return nil, err
+	}
+
+	found := &batchv1.Job{}
+	err = r.Get(context.TODO(), types.NamespacedName{Name: hook.Name, Namespace: hook.Namespace}, found)
+	if err == nil {
+		return found, nil
+	} else if !errors.IsNotFound(err) {
+		r.Log.Error(err, "Failed to get the job")
+		r.Event(ray, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the job %s: %v", hook.Name, err))
+		return nil, err
+	}
+
+	r.Log.V(1).Info("Creating Job", "namespace", hook.Namespace, "name", hook.Name)
+	if err := r.Create(context.TODO(), hook); err != nil {
+		r.Log.Error(err, "Failed to create the job")
+		r.Event(ray, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the job %s: %v", hook.Name, err))
+		return nil, err
+	}
+	r.Event(ray, consts.EventNormal, consts.ReasonCreate,
+		fmt.Sprintf("Successfully create the job %s", hook.Name))
+	return hook, nil
+}
+
+func (r *RayReconciler) updateTerminatingStatus(ray *rayv1.Ray, old *rayv1.RayStatus) error {
+	if equality.Semantic.DeepEqual(&ray.Status, old) {
+		return nil
+	}
+	now := metav1.Now()
+	ray.Status.LastReconcileTime = &now
+	r.Log.V(1).Info("Updating Ray status", "namespace", ray.Namespace,
+		"name", ray.Name,
+		"status", ray.Status)
+	if err := r.Status().Update(context.TODO(), ray); err != nil {
+		r.Log.Error(err, "Failed to update the status for ray", "instance", ray.Name)
+		r.Event(ray, consts.EventWarning, consts.ReasonReconcileFailed,
+			fmt.Sprintf("Failed to update the status: %v", err))
+		return err
+	}
+	return nil
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(slice []string, s string) []string {
+	result := []string{}
+	for _, item := range slice {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/autoscaler"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+func newTestTerminatingRay(name string, deletedAgo time.Duration) *rayv1.Ray {
+	ray := newTestRay(name)
+	ray.Spec.Termination = &rayv1.TerminationSpec{}
+	ray.Finalizers = []string{FinalizerGracefulTermination}
+	deleted := metav1.NewTime(time.Now().Add(-deletedAgo))
+	ray.DeletionTimestamp = &deleted
+	return ray
+}
+
+func newTestWorkerPod(ray *rayv1.Ray, name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ray.Namespace,
+			Labels:    composer.GetWorkerPodLabels(ray.Name, rayv1.DefaultWorkerGroupName),
+		},
+	}
+}
+
+func TestDrainWorkers(t *testing.T) {
+	ray := newTestTerminatingRay("test", 0)
+	other := newTestRay("other")
+	replicas := int32(3)
+
+	owned := newTestWorkerDeployment(ray, "cpu", map[string]string{consts.LabelRay: ray.Name}, true)
+	owned.Spec.Replicas = &replicas
+	otherRay := newTestWorkerDeployment(other, "cpu", map[string]string{consts.LabelRay: other.Name}, true)
+	otherRay.Spec.Replicas = &replicas
+
+	r := newTestReconciler(ray, owned, otherRay,
+		newTestWorkerPod(ray, "test-worker-a"), newTestWorkerPod(ray, "test-worker-b"),
+		newTestWorkerPod(other, "other-worker-a"))
+	remaining, err := r.drainWorkers(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if remaining != 2 {
+		t.Errorf("expected 2 remaining workers, got %d", remaining)
+	}
+
+	for _, c := range []struct {
+		deploy   *appsv1.Deployment
+		replicas int32
+	}{
+		{owned, 0},
+		{otherRay, replicas},
+	} {
+		found := &appsv1.Deployment{}
+		if err := r.Get(context.TODO(), types.NamespacedName{
+			Name: c.deploy.Name, Namespace: c.deploy.Namespace}, found); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *found.Spec.Replicas != c.replicas {
+			t.Errorf("expected %d replicas of %s, got %d", c.replicas, c.deploy.Name, *found.Spec.Replicas)
+		}
+	}
+}
+
+func TestFinalize(t *testing.T) {
+	cases := []struct {
+		name       string
+		deletedAgo time.Duration
+		pods       []runtime.Object
+		released   bool
+	}{
+		{
+			name:       "drained",
+			deletedAgo: time.Second,
+			released:   true,
+		},
+		{
+			name:       "draining",
+			deletedAgo: time.Second,
+			pods:       []runtime.Object{newTestWorkerPod(newTestRay("test"), "test-worker-a")},
+			released:   false,
+		},
+		{
+			name:       "grace period exceeded",
+			deletedAgo: time.Hour,
+			pods:       []runtime.Object{newTestWorkerPod(newTestRay("test"), "test-worker-a")},
+			released:   true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestTerminatingRay("test", c.deletedAgo)
+			head, err := newTestReconciler().Composer.DesiredHead(ray)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			r := newTestReconciler(append(c.pods, ray, head)...)
+
+			result, err := r.finalize(ray)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			found := &rayv1.Ray{}
+			if err := r.Get(context.TODO(), types.NamespacedName{
+				Name: ray.Name, Namespace: ray.Namespace}, found); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			released := !containsString(found.Finalizers, FinalizerGracefulTermination)
+			if released != c.released {
+				t.Errorf("expected the finalizer released %v, got the finalizers %v", c.released, found.Finalizers)
+			}
+			headErr := r.Get(context.TODO(), types.NamespacedName{
+				Name: head.Name, Namespace: head.Namespace}, &appsv1.Deployment{})
+			if (headErr != nil) != c.released {
+				t.Errorf("expected the head deleted %v, got %v", c.released, headErr)
+			}
+			if !c.released && (result.RequeueAfter <= 0 || found.Status.Phase != rayv1.RayTerminating) {
+				t.Errorf("expected to wait for the workers in the phase %s, got %v and %s",
+					rayv1.RayTerminating, result, found.Status.Phase)
+			}
+		})
+	}
+}
+
+// rayUpdateRecorder records the Rays updated through the client, the status updates
+// are not recorded.
+type rayUpdateRecorder struct {
+	client.Client
+	updated []*rayv1.Ray
+}
+
+func (c *rayUpdateRecorder) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if ray, ok := obj.(*rayv1.Ray); ok {
+		c.updated = append(c.updated, ray.DeepCopy())
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestSyncFinalizerNotPersistDefaults(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		ray := &rayv1.Ray{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "default",
+				UID:       "test-uid",
+			},
+			Spec: rayv1.RaySpec{
+				RayVersion: "2.9.0",
+			},
+		}
+		if enabled {
+			ray.Spec.Termination = &rayv1.TerminationSpec{}
+		} else {
+			ray.Finalizers = []string{FinalizerGracefulTermination}
+		}
+		r := newTestReconciler(ray)
+		r.Autoscaler = autoscaler.New(r.EventRecorder, logf.NullLogger{}, fakeDemandSource{})
+		recorder := &rayUpdateRecorder{Client: r.Client}
+		r.Client = recorder
+
+		if _, err := r.sync(ray); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(recorder.updated) != 1 {
+			t.Fatalf("expected the Ray to be updated once, got %d updates", len(recorder.updated))
+		}
+		updated := recorder.updated[0]
+		if got := containsString(updated.Finalizers, FinalizerGracefulTermination); got != enabled {
+			t.Errorf("expected the finalizer %v, got the finalizers %v", enabled, updated.Finalizers)
+		}
+		if updated.Spec.Head != nil || updated.Spec.Worker.Template != nil {
+			t.Errorf("expected the defaults not to be persisted, got the spec %+v", updated.Spec)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// sync handles all requests.
+func (r *RayJobReconciler) sync(job *rayv1.RayJob) (ctrl.Result, error) {
+	r.Log.V(1).Info("Sync the object RayJob", "namespace", job.Namespace, "instance", job.Name)
+	defer r.Log.V(1).Info("Finished syncing RayJob", "namespace", job.Namespace, "instance", job.Name)
+
+	old := job.Status.DeepCopy()
+	status := &job.Status
+	if status.StartTime == nil {
+		now := metav1.Now()
+		status.StartTime = &now
+		status.JobStatus = rayv1.RayJobPending
+	}
+	if job.Generation > status.ObservedGeneration {
+		status.ObservedGeneration = job.Generation
+	}
+
+	if status.IsFinished() {
+		return r.cleanup(job, old)
+	}
+
+	if err := r.Validator.ValidateRayJob(job); err != nil {
+		r.finish(job, rayv1.RayJobFailed, err.Error())
+		// Do not requeue the requests since we cannot deal with it.
+		return ctrl.Result{}, r.updateJobStatus(job, old)
+	}
+
+	desiredRay, err := r.Composer.DesiredRayForJob(job)
+	if err != nil {
+		r.Log.Error(err, "Failed to compose the ray")
+		r.Event(job, consts.EventWarning, consts.ReasonComposeFailed,
+			fmt.Sprintf("Failed to compose the ray: %v", err))
+		return ctrl.Result{}, err
+	}
+	actualRay, err := r.createRayIfNotExists(job, desiredRay)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	status.RayName = actualRay.Name
+
+	if actualRay.Status.Phase == rayv1.RayFailed {
+		r.finish(job, rayv1.RayJobFailed,
+			fmt.Sprintf("The Ray %s failed: %s", actualRay.Name, actualRay.Status.Message))
+		return r.cleanup(job, old)
+	}
+	// The submitter is tracked once the job is running, even if the Ray becomes
+	// unhealthy, since the job may still finish.
+	if status.JobStatus == rayv1.RayJobPending && !isRayHealthy(actualRay) {
+		status.Message = fmt.Sprintf("Waiting for the Ray %s to be healthy", actualRay.Name)
+		// The job is synced again when the status of the Ray changes.
+		return ctrl.Result{}, r.updateJobStatus(job, old)
+	}
+
+	desiredSubmitter, err := r.Composer.DesiredSubmitter(job, actualRay)
+	if err != nil {
+		r.finish(job, rayv1.RayJobFailed, err.Error())
+		return ctrl.Result{}, r.updateJobStatus(job, old)
+	}
+	submitter, err := r.createSubmitterIfNotExists(job, desiredSubmitter)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch {
+	case isJobConditionTrue(submitter, batchv1.JobComplete) || submitter.Status.Succeeded > 0:
+		r.finish(job, rayv1.RayJobSucceeded,
+			fmt.Sprintf("The job %s succeeded", submitter.Name))
+	case isJobConditionTrue(submitter, batchv1.JobFailed) || submitter.Status.Failed > 0:
+		r.finish(job, rayv1.RayJobFailed,
+			fmt.Sprintf("The job %s failed", submitter.Name))
+	default:
+		status.JobStatus = rayv1.RayJobRunning
+		status.Message = fmt.Sprintf("The job %s is running", submitter.Name)
+	}
+
+	if status.IsFinished() {
+		return r.cleanup(job, old)
+	}
+	return ctrl.Result{}, r.updateJobStatus(job, old)
+}
+
+// finish marks the job finished.
+func (r *RayJobReconciler) finish(job *rayv1.RayJob, phase rayv1.RayJobPhase, message string) {
+	now := metav1.Now()
+	job.Status.JobStatus = phase
+	job.Status.Message = message
+	job.Status.CompletionTime = &now
+
+	eventType := consts.EventNormal
+	if phase == rayv1.RayJobFailed {
+		eventType = consts.EventWarning
+	}
+	r.Event(job, eventType, string(phase), message)
+}
+
+// cleanup deletes the Ray cluster after the TTL if ShutdownAfterJobFinishes is true.
+func (r *RayJobReconciler) cleanup(job *rayv1.RayJob, old *rayv1.RayJobStatus) (ctrl.Result, error) {
+	status := &job.Status
+	if !job.Spec.ShutdownAfterJobFinishes || status.RayDeleted || status.RayName == "" {
+		return ctrl.Result{}, r.updateJobStatus(job, old)
+	}
+
+	ttl := time.Duration(0)
+	if job.Spec.TTLSecondsAfterFinished != nil {
+		ttl = time.Duration(*job.Spec.TTLSecondsAfterFinished) * time.Second
+	}
+	if remaining := status.CompletionTime.Add(ttl).Sub(time.Now()); remaining > 0 {
+		return ctrl.Result{
+			RequeueAfter: remaining,
+		}, r.updateJobStatus(job, old)
+	}
+
+	ray := &rayv1.Ray{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: status.RayName, Namespace: job.Namespace}, ray)
+	if err == nil {
+		r.Log.V(1).Info("Deleting Ray", "namespace", ray.Namespace, "name", ray.Name)
+		err = r.Delete(context.TODO(), ray)
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		r.Log.Error(err, "Failed to delete the ray")
+		r.Event(job, consts.EventWarning, consts.ReasonDelete,
+			fmt.Sprintf("Failed to delete the ray %s: %v", status.RayName, err))
+		return ctrl.Result{}, err
+	}
+	r.Event(job, consts.EventNormal, consts.ReasonDelete,
+		fmt.Sprintf("Successfully delete the ray %s", status.RayName))
+	status.RayDeleted = true
+	return ctrl.Result{}, r.updateJobStatus(job, old)
+}
+
+// createRayIfNotExists creates the Ray cluster. The cluster is not updated
+// since it is ephemeral.
+func (r *RayJobReconciler) createRayIfNotExists(job *rayv1.RayJob, ray *rayv1.Ray) (*rayv1.Ray, error) {
+	found := &rayv1.Ray{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: ray.Name, Namespace: ray.Namespace}, found)
+	if err == nil {
+		return found, nil
+	} else if !errors.IsNotFound(err) {
+		r.Log.Error(err, "Failed to get the ray")
+		r.Event(job, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the ray %s: %v", ray.Name, err))
+		return nil, err
+	}
+
+	r.Log.V(1).Info("Creating Ray", "namespace", ray.Namespace, "name", ray.Name)
+	if err := r.Create(context.TODO(), ray); err != nil {
+		r.Log.Error(err, "Failed to create the ray")
+		r.Event(job, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the ray %s: %v", ray.Name, err))
+		return nil, err
+	}
+	r.Event(job, consts.EventNormal, consts.ReasonCreate,
+		fmt.Sprintf("Successfully create the ray %s", ray.Name))
+	return ray, nil
+}
+
+// createSubmitterIfNotExists creates the submitter. The submitter is not updated
+// since the entrypoint must not be run twice.
+func (r *RayJobReconciler) createSubmitterIfNotExists(job *rayv1.RayJob,
+	submitter *batchv1.Job) (*batchv1.Job, error) {
+	found := &batchv1.Job{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: submitter.Name, Namespace: submitter.Namespace}, found)
+	if err == nil {
+		return found, nil
+	} else if !errors.IsNotFound(err) {
+		r.Log.Error(err, "Failed to get the job")
+		r.Event(job, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the job %s: %v", submitter.Name, err))
+		return nil, err
+	}
+
+	r.Log.V(1).Info("Creating Job", "namespace", submitter.Namespace, "name", submitter.Name)
+	if err := r.Create(context.TODO(), submitter); err != nil {
+		r.Log.Error(err, "Failed to create the job")
+		r.Event(job, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the job %s: %v", submitter.Name, err))
+		return nil, err
+	}
+	r.Event(job, consts.EventNormal, consts.ReasonCreate,
+		fmt.Sprintf("Successfully create the job %s", submitter.Name))
+	return submitter, nil
+}
+
+func (r *RayJobReconciler) updateJobStatus(job *rayv1.RayJob, old *rayv1.RayJobStatus) error {
+	if equality.Semantic.DeepEqual(&job.Status, old) {
+		return nil
+	}
+	r.Log.V(1).Info("Updating RayJob status", "namespace", job.Namespace,
+		"name", job.Name,
+		"status", job.Status)
+	return r.Status().Update(context.TODO(), job)
+}
+
+func isRayHealthy(ray *rayv1.Ray) bool {
+	for _, condition := range ray.Status.Conditions {
+		if condition.Type == rayv1.RayHealth {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func isJobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+	"github.com/kubeflow/ray-operator/pkg/validator"
+)
+
+func newTestRayJobReconciler(composerScheme *runtime.Scheme, objs ...runtime.Object) (*RayJobReconciler,
+	*record.FakeRecorder) {
+	s := runtime.NewScheme()
+	_ = scheme.AddToScheme(s)
+	_ = rayv1.AddToScheme(s)
+	if composerScheme == nil {
+		composerScheme = s
+	}
+	recorder := record.NewFakeRecorder(100)
+	return &RayJobReconciler{
+		Client:        fake.NewFakeClientWithScheme(s, objs...),
+		EventRecorder: recorder,
+		Validator:     validator.New(recorder, logf.NullLogger{}),
+		Composer:      composer.New(recorder, logf.NullLogger{}, composerScheme),
+		Log:           logf.NullLogger{},
+	}, recorder
+}
+
+func newTestRayJob() *rayv1.RayJob {
+	return &rayv1.RayJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			UID:       "test-uid",
+		},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint: "python main.py",
+			RaySpec: rayv1.RaySpec{
+				RayVersion: "2.9.0",
+			},
+		},
+	}
+}
+
+func TestRayJobSyncCreatesRay(t *testing.T) {
+	job := newTestRayJob()
+	r, _ := newTestRayJobReconciler(nil, job)
+	if _, err := r.sync(job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ray := &rayv1.Ray{}
+	if err := r.Get(context.TODO(), types.NamespacedName{
+		Name: composer.GetRayNameForJob(job.Name), Namespace: job.Namespace}, ray); err != nil {
+		t.Fatalf("expected the Ray to be created, got %v", err)
+	}
+	if ray.Spec.Head == nil || ray.Spec.Head.Template == nil {
+		t.Errorf("expected the Ray to be defaulted, got %v", ray.Spec.Head)
+	}
+	if job.Status.JobStatus != rayv1.RayJobPending {
+		t.Errorf("expected the job to be pending, got %s", job.Status.JobStatus)
+	}
+}
+
+func TestRayJobSyncComposeFailed(t *testing.T) {
+	job := newTestRayJob()
+	// The owner reference of the Ray cannot be set without the RayJob in the scheme.
+	r, recorder := newTestRayJobReconciler(runtime.NewScheme(), job)
+	if _, err := r.sync(job); err == nil {
+		t.Fatal("expected the error to be returned")
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, consts.EventWarning+" "+consts.ReasonComposeFailed) {
+			t.Errorf("expected a warning event of %s, got %s", consts.ReasonComposeFailed, event)
+		}
+	default:
+		t.Error("expected a warning event")
+	}
+}
+
+func TestRayJobSyncRayFailed(t *testing.T) {
+	job := newTestRayJob()
+	ray := &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      composer.GetRayNameForJob(job.Name),
+			Namespace: job.Namespace,
+		},
+		Status: rayv1.RayStatus{
+			Phase:   rayv1.RayFailed,
+			Message: "the head failed",
+		},
+	}
+	r, _ := newTestRayJobReconciler(nil, job, ray)
+	if _, err := r.sync(job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status.JobStatus != rayv1.RayJobFailed {
+		t.Errorf("expected the job to fail, got %s", job.Status.JobStatus)
+	}
+	if job.Status.CompletionTime == nil {
+		t.Error("expected the completion time to be set")
+	}
+}
+
+func TestRayJobSyncKeepsRunning(t *testing.T) {
+	job := newTestRayJob()
+	job.Status.StartTime = &metav1.Time{}
+	job.Status.JobStatus = rayv1.RayJobRunning
+	ray := &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      composer.GetRayNameForJob(job.Name),
+			Namespace: job.Namespace,
+		},
+		Spec: job.Spec.RaySpec,
+		Status: rayv1.RayStatus{
+			Phase: rayv1.RayDegraded,
+		},
+	}
+	ray.Default()
+	submitter := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      composer.GetSubmitterName(job.Name),
+			Namespace: job.Namespace,
+		},
+	}
+	r, _ := newTestRayJobReconciler(nil, job, ray, submitter)
+	if _, err := r.sync(job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The job keeps running while the Ray is unhealthy.
+	if job.Status.JobStatus != rayv1.RayJobRunning {
+		t.Errorf("expected the job to be running, got %s", job.Status.JobStatus)
+	}
+}
@@ -0,0 +1,291 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+	"github.com/kubeflow/ray-operator/pkg/serve"
+)
+
+// sync handles all requests. The cluster which serves the traffic is replaced in
+// the following steps when the cluster spec is changed:
+//  1. Create the pending cluster and wait for it to be healthy.
+//  2. Deploy the serve config to the pending cluster and wait for the serve
+//     applications to be running.
+//  3. Switch the selector of the stable service to the pending cluster, which
+//     becomes the active cluster.
+//  4. Delete the other clusters owned by the RayService.
+func (r *RayServiceReconciler) sync(svc *rayv1.RayService) (ctrl.Result, error) {
+	r.Log.V(1).Info("Sync the object RayService", "namespace", svc.Namespace, "instance", svc.Name)
+	defer r.Log.V(1).Info("Finished syncing RayService", "namespace", svc.Namespace, "instance", svc.Name)
+
+	old := svc.Status.DeepCopy()
+	status := &svc.Status
+	if svc.Generation > status.ObservedGeneration {
+		status.ObservedGeneration = svc.Generation
+	}
+
+	if err := r.Validator.ValidateRayService(svc); err != nil {
+		status.Message = err.Error()
+		// Do not requeue the requests since we cannot deal with it.
+		return ctrl.Result{}, r.updateServiceStatus(svc, old)
+	}
+	config, err := yaml.YAMLToJSON([]byte(svc.Spec.ServeConfig))
+	if err != nil {
+		// The serve config is validated above, thus it is not expected.
+		return ctrl.Result{}, err
+	}
+	configHash, err := composer.GetHash(string(config))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	desiredRay, err := r.Composer.DesiredRayForService(svc)
+	if err != nil {
+		r.Log.Error(err, "Failed to compose the ray")
+		r.Event(svc, consts.EventWarning, consts.ReasonComposeFailed,
+			fmt.Sprintf("Failed to compose the ray: %v", err))
+		return ctrl.Result{}, err
+	}
+	status.ServiceName = composer.GetServeServiceName(svc.Name)
+
+	// The cluster spec is not changed, keep serving on the active cluster.
+	if status.ActiveCluster != nil && status.ActiveCluster.RayName == desiredRay.Name {
+		status.PendingCluster = nil
+		if err := r.deleteStaleRays(svc); err != nil {
+			return ctrl.Result{}, r.requeueWithStatus(svc, old, err)
+		}
+		ray, err := r.createRayIfNotExists(svc, desiredRay)
+		if err != nil {
+			return ctrl.Result{}, r.requeueWithStatus(svc, old, err)
+		}
+		if !isRayHealthy(ray) {
+			status.Message = fmt.Sprintf("Waiting for the active Ray %s to be healthy", ray.Name)
+			return ctrl.Result{RequeueAfter: serve.SyncPeriod}, r.updateServiceStatus(svc, old)
+		}
+		if err := r.syncServe(svc, ray, status.ActiveCluster, config, configHash); err != nil {
+			status.Message = fmt.Sprintf("Failed to sync the serve applications on the active Ray %s: %v",
+				ray.Name, err)
+			return ctrl.Result{}, r.requeueWithStatus(svc, old, err)
+		}
+		if err := r.switchTo(svc, ray); err != nil {
+			return ctrl.Result{}, r.requeueWithStatus(svc, old, err)
+		}
+		status.Message = fmt.Sprintf("Serving on the Ray %s", ray.Name)
+		return ctrl.Result{RequeueAfter: serve.SyncPeriod}, r.updateServiceStatus(svc, old)
+	}
+
+	// The cluster spec is changed, prepare the pending cluster.
+	if status.PendingCluster == nil || status.PendingCluster.RayName != desiredRay.Name {
+		status.PendingCluster = &rayv1.RayServiceClusterStatus{
+			RayName: desiredRay.Name,
+		}
+	}
+	if err := r.deleteStaleRays(svc); err != nil {
+		return ctrl.Result{}, r.requeueWithStatus(svc, old, err)
+	}
+	ray, err := r.createRayIfNotExists(svc, desiredRay)
+	if err != nil {
+		return ctrl.Result{}, r.requeueWithStatus(svc, old, err)
+	}
+	if !isRayHealthy(ray) {
+		status.Message = fmt.Sprintf("Waiting for the pending Ray %s to be healthy", ray.Name)
+		// The service is synced again when the status of the Ray changes.
+		return ctrl.Result{}, r.updateServiceStatus(svc, old)
+	}
+	if err := r.syncServe(svc, ray, status.PendingCluster, config, configHash); err != nil {
+		status.Message = fmt.Sprintf("Failed to sync the serve applications on the pending Ray %s: %v",
+			ray.Name, err)
+		return ctrl.Result{}, r.requeueWithStatus(svc, old, err)
+	}
+	if !status.PendingCluster.ServeHealthy {
+		status.Message = fmt.Sprintf("Waiting for the serve applications on the pending Ray %s to be running",
+			ray.Name)
+		return ctrl.Result{RequeueAfter: serve.SyncPeriod}, r.updateServiceStatus(svc, old)
+	}
+
+	if err := r.switchTo(svc, ray); err != nil {
+		return ctrl.Result{}, r.requeueWithStatus(svc, old, err)
+	}
+	previous := ""
+	if status.ActiveCluster != nil {
+		previous = status.ActiveCluster.RayName
+	}
+	status.ActiveCluster = status.PendingCluster
+	status.PendingCluster = nil
+	status.Message = fmt.Sprintf("Serving on the Ray %s", ray.Name)
+	r.Event(svc, consts.EventNormal, consts.ReasonSwitched,
+		fmt.Sprintf("Switched the traffic from the ray %q to %s", previous, ray.Name))
+	if err := r.deleteStaleRays(svc); err != nil {
+		return ctrl.Result{}, r.requeueWithStatus(svc, old, err)
+	}
+	return ctrl.Result{RequeueAfter: serve.SyncPeriod}, r.updateServiceStatus(svc, old)
+}
+
+// syncServe deploys the serve config to the Ray cluster if it is not deployed yet,
+// and updates the status of the serve applications.
+func (r *RayServiceReconciler) syncServe(svc *rayv1.RayService, ray *rayv1.Ray,
+	cluster *rayv1.RayServiceClusterStatus, config []byte, configHash string) error {
+	if cluster.ServeConfigHash != configHash || len(cluster.Applications) == 0 {
+		r.Log.V(1).Info("Deploying serve config", "namespace", ray.Namespace, "name", ray.Name)
+		if err := r.Serve.UpdateConfig(ray, config); err != nil {
+			r.Log.Error(err, "Failed to deploy the serve config")
+			r.Event(svc, consts.EventWarning, consts.ReasonServeDeployed,
+				fmt.Sprintf("Failed to deploy the serve config to the ray %s: %v", ray.Name, err))
+			cluster.ServeHealthy = false
+			return err
+		}
+		if cluster.ServeConfigHash != configHash {
+			r.Event(svc, consts.EventNormal, consts.ReasonServeDeployed,
+				fmt.Sprintf("Successfully deploy the serve config to the ray %s", ray.Name))
+		}
+		cluster.ServeConfigHash = configHash
+	}
+
+	applications, err := r.Serve.GetApplications(ray)
+	if err != nil {
+		r.Log.Error(err, "Failed to get the serve applications")
+		r.Event(svc, consts.EventWarning, consts.ReasonServeUnavailable,
+			fmt.Sprintf("Failed to get the serve applications from the ray %s: %v", ray.Name, err))
+		cluster.ServeHealthy = false
+		return err
+	}
+	cluster.Applications = applications
+	cluster.ServeHealthy = serve.IsHealthy(applications)
+	return nil
+}
+
+// switchTo points the stable service to the head of the Ray cluster.
+func (r *RayServiceReconciler) switchTo(svc *rayv1.RayService, ray *rayv1.Ray) error {
+	service, err := r.Composer.DesiredServeService(svc, ray)
+	if err != nil {
+		return err
+	}
+
+	found := &corev1.Service{}
+	err = r.Get(context.TODO(), types.NamespacedName{Name: service.Name, Namespace: service.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		r.Log.V(1).Info("Creating Service", "namespace", service.Namespace, "name", service.Name)
+		setManagedKeys(service, service)
+		if err := r.Create(context.TODO(), service); err != nil {
+			r.Log.Error(err, "Failed to create the service")
+			r.Event(svc, consts.EventWarning, consts.ReasonCreate,
+				fmt.Sprintf("Failed to create the service %s: %v", service.Name, err))
+			return err
+		}
+		r.Event(svc, consts.EventNormal, consts.ReasonCreate,
+			fmt.Sprintf("Successfully create the service %s", service.Name))
+		return nil
+	} else if err != nil {
+		r.Log.Error(err, "Failed to get the service")
+		return err
+	}
+
+	if changes := diffService(service, found); len(changes) != 0 {
+		r.Log.V(1).Info("Updating service", "namespace", service.Namespace, "name", service.Name,
+			"changes", changes)
+		if err := r.Update(context.TODO(), mergeService(service, found)); err != nil {
+			r.Log.Error(err, "Failed to update the service")
+			r.Event(svc, consts.EventWarning, consts.ReasonUpdate,
+				fmt.Sprintf("Failed to update the service %s: %v", service.Name, err))
+			return err
+		}
+		r.Event(svc, consts.EventNormal, consts.ReasonUpdate,
+			fmt.Sprintf("Successfully update the service %s, changed: %s",
+				service.Name, strings.Join(changes, ", ")))
+	}
+	return nil
+}
+
+// createRayIfNotExists creates the Ray cluster. The cluster is not updated since
+// a new cluster is created when the cluster spec is changed.
+func (r *RayServiceReconciler) createRayIfNotExists(svc *rayv1.RayService, ray *rayv1.Ray) (*rayv1.Ray, error) {
+	found := &rayv1.Ray{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: ray.Name, Namespace: ray.Namespace}, found)
+	if err == nil {
+		return found, nil
+	} else if !errors.IsNotFound(err) {
+		r.Log.Error(err, "Failed to get the ray")
+		return nil, err
+	}
+
+	r.Log.V(1).Info("Creating Ray", "namespace", ray.Namespace, "name", ray.Name)
+	if err := r.Create(context.TODO(), ray); err != nil {
+		r.Log.Error(err, "Failed to create the ray")
+		r.Event(svc, consts.EventWarning, consts.ReasonCreate,
+			fmt.Sprintf("Failed to create the ray %s: %v", ray.Name, err))
+		return nil, err
+	}
+	r.Event(svc, consts.EventNormal, consts.ReasonCreate,
+		fmt.Sprintf("Successfully create the ray %s", ray.Name))
+	return ray, nil
+}
+
+// deleteStaleRays deletes the Ray clusters owned by the RayService which are
+// neither active nor pending.
+func (r *RayServiceReconciler) deleteStaleRays(svc *rayv1.RayService) error {
+	rays := &rayv1.RayList{}
+	if err := r.List(context.TODO(), rays, client.InNamespace(svc.Namespace)); err != nil {
+		r.Log.Error(err, "Failed to list the rays")
+		return err
+	}
+
+	keep := map[string]bool{}
+	for _, cluster := range []*rayv1.RayServiceClusterStatus{
+		svc.Status.ActiveCluster, svc.Status.PendingCluster} {
+		if cluster != nil {
+			keep[cluster.RayName] = true
+		}
+	}
+	for i := range rays.Items {
+		ray := &rays.Items[i]
+		if keep[ray.Name] || !metav1.IsControlledBy(ray, svc) {
+			continue
+		}
+		r.Log.V(1).Info("Deleting Ray", "namespace", ray.Namespace, "name", ray.Name)
+		if err := r.Delete(context.TODO(), ray); err != nil && !errors.IsNotFound(err) {
+			r.Log.Error(err, "Failed to delete the ray")
+			r.Event(svc, consts.EventWarning, consts.ReasonDelete,
+				fmt.Sprintf("Failed to delete the ray %s: %v", ray.Name, err))
+			return err
+		}
+		r.Event(svc, consts.EventNormal, consts.ReasonDelete,
+			fmt.Sprintf("Successfully delete the ray %s", ray.Name))
+	}
+	return nil
+}
+
+// requeueWithStatus updates the status and returns the error, thus the request is
+// requeued with backoff while the status reports the progress so far.
+func (r *RayServiceReconciler) requeueWithStatus(svc *rayv1.RayService,
+	old *rayv1.RayServiceStatus, err error) error {
+	if err := r.updateServiceStatus(svc, old); err != nil {
+		r.Log.Error(err, "Failed to update the status for rayservice", "instance", svc.Name)
+	}
+	return err
+}
+
+func (r *RayServiceReconciler) updateServiceStatus(svc *rayv1.RayService, old *rayv1.RayServiceStatus) error {
+	if equality.Semantic.DeepEqual(&svc.Status, old) {
+		return nil
+	}
+	now := metav1.Now()
+	svc.Status.LastReconcileTime = &now
+	r.Log.V(1).Info("Updating RayService status", "namespace", svc.Namespace,
+		"name", svc.Name,
+		"status", svc.Status)
+	return r.Status().Update(context.TODO(), svc)
+}
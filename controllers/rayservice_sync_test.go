@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+	"github.com/kubeflow/ray-operator/pkg/serve"
+	"github.com/kubeflow/ray-operator/pkg/validator"
+)
+
+// fakeServe is a serve.Interface which returns the configured results.
+type fakeServe struct {
+	updateErr    error
+	applications map[string]string
+	getErr       error
+	updated      int
+}
+
+func (s *fakeServe) UpdateConfig(ray *rayv1.Ray, config []byte) error {
+	s.updated++
+	return s.updateErr
+}
+
+func (s *fakeServe) GetApplications(ray *rayv1.Ray) (map[string]string, error) {
+	return s.applications, s.getErr
+}
+
+func newTestRayService() *rayv1.RayService {
+	return &rayv1.RayService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			UID:       "test-uid",
+		},
+		Spec: rayv1.RayServiceSpec{
+			ServeConfig: "applications:\n- name: app\n  import_path: app:deployment\n",
+			RaySpec: rayv1.RaySpec{
+				RayVersion: "2.9.0",
+			},
+		},
+	}
+}
+
+// newTestRayServiceReconciler returns a reconciler whose healthy pending Ray of the
+// RayService already exists.
+func newTestRayServiceReconciler(t *testing.T, svc *rayv1.RayService,
+	s serve.Interface) (*RayServiceReconciler, *record.FakeRecorder, *rayv1.Ray) {
+	sch := runtime.NewScheme()
+	_ = scheme.AddToScheme(sch)
+	_ = rayv1.AddToScheme(sch)
+	recorder := record.NewFakeRecorder(100)
+	c := composer.New(recorder, logf.NullLogger{}, sch)
+
+	ray, err := c.DesiredRayForService(svc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ray.Status.Conditions = []rayv1.RayCondition{
+		{Type: rayv1.RayHealth, Status: corev1.ConditionTrue},
+	}
+	return &RayServiceReconciler{
+		Client:        fake.NewFakeClientWithScheme(sch, svc, ray),
+		EventRecorder: recorder,
+		Composer:      c,
+		Validator:     validator.New(recorder, logf.NullLogger{}),
+		Serve:         s,
+		Log:           logf.NullLogger{},
+	}, recorder, ray
+}
+
+func hasEvent(recorder *record.FakeRecorder, eventType, reason string) bool {
+	for {
+		select {
+		case event := <-recorder.Events:
+			if strings.HasPrefix(event, eventType+" "+reason+" ") {
+				return true
+			}
+		default:
+			return false
+		}
+	}
+}
+
+func TestRayServiceSyncServeFailed(t *testing.T) {
+	cases := []struct {
+		name   string
+		serve  *fakeServe
+		reason string
+	}{
+		{
+			name:   "update config failed",
+			serve:  &fakeServe{updateErr: fmt.Errorf("connection refused")},
+			reason: consts.ReasonServeDeployed,
+		},
+		{
+			name:   "get applications failed",
+			serve:  &fakeServe{getErr: fmt.Errorf("connection refused")},
+			reason: consts.ReasonServeUnavailable,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := newTestRayService()
+			r, recorder, ray := newTestRayServiceReconciler(t, svc, c.serve)
+			if _, err := r.sync(svc); err == nil {
+				t.Fatal("expected the error to be returned")
+			}
+			if !hasEvent(recorder, consts.EventWarning, c.reason) {
+				t.Errorf("expected a warning event of %s", c.reason)
+			}
+			if svc.Status.PendingCluster == nil || svc.Status.PendingCluster.RayName != ray.Name {
+				t.Errorf("expected the pending cluster %s, got %v", ray.Name, svc.Status.PendingCluster)
+			}
+			if svc.Status.ActiveCluster != nil {
+				t.Errorf("expected no active cluster, got %v", svc.Status.ActiveCluster)
+			}
+			if !strings.Contains(svc.Status.Message, "connection refused") {
+				t.Errorf("expected the error in the message, got %q", svc.Status.Message)
+			}
+		})
+	}
+}
+
+func TestRayServiceSyncSwitched(t *testing.T) {
+	svc := newTestRayService()
+	s := &fakeServe{applications: map[string]string{"app": serve.ApplicationRunning}}
+	r, recorder, ray := newTestRayServiceReconciler(t, svc, s)
+	result, err := r.sync(svc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != serve.SyncPeriod {
+		t.Errorf("expected to requeue after %v, got %v", serve.SyncPeriod, result.RequeueAfter)
+	}
+	if s.updated != 1 {
+		t.Errorf("expected the serve config to be deployed once, got %d", s.updated)
+	}
+	if svc.Status.ActiveCluster == nil || svc.Status.ActiveCluster.RayName != ray.Name {
+		t.Errorf("expected the active cluster %s, got %v", ray.Name, svc.Status.ActiveCluster)
+	}
+	if !hasEvent(recorder, consts.EventNormal, consts.ReasonSwitched) {
+		t.Errorf("expected an event of %s", consts.ReasonSwitched)
+	}
+	service := &corev1.Service{}
+	if err := r.Get(context.TODO(), types.NamespacedName{
+		Name: composer.GetServeServiceName(svc.Name), Namespace: svc.Namespace}, service); err != nil {
+		t.Errorf("expected the stable service to be created, got %v", err)
+	}
+}
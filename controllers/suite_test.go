@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	rayv2 "github.com/kubeflow/ray-operator/api/v2"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -63,6 +64,9 @@ var _ = BeforeSuite(func(done Done) {
 	err = rayv1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
+	err = rayv2.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
 	// +kubebuilder:scaffold:scheme
 
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
@@ -0,0 +1,57 @@
+#!/usr/bin/env bash
+
+# Copyright 2019 The Kubeflow community.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Generates the clientset, the informers and the listers of the Ray API in pkg/client.
+# The generators of k8s.io/code-generator must be in PATH, see `make generate-client`.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+MODULE=github.com/kubeflow/ray-operator
+INPUTS="${MODULE}/api/v1,${MODULE}/api/v2"
+OUTPUT_BASE=$(mktemp -d)
+trap 'rm -rf "${OUTPUT_BASE}"' EXIT
+
+client-gen \
+  --clientset-name versioned \
+  --input-base "" \
+  --input "${INPUTS}" \
+  --output-package "${MODULE}/pkg/client/clientset" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${SCRIPT_ROOT}/hack/boilerplate.go.txt"
+
+lister-gen \
+  --input-dirs "${INPUTS}" \
+  --output-package "${MODULE}/pkg/client/listers" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${SCRIPT_ROOT}/hack/boilerplate.go.txt"
+
+informer-gen \
+  --input-dirs "${INPUTS}" \
+  --versioned-clientset-package "${MODULE}/pkg/client/clientset/versioned" \
+  --listers-package "${MODULE}/pkg/client/listers" \
+  --output-package "${MODULE}/pkg/client/informers" \
+  --output-base "${OUTPUT_BASE}" \
+  --go-header-file "${SCRIPT_ROOT}/hack/boilerplate.go.txt"
+
+# The fake clientset which tracks the seeded objects by their resources is not generated.
+KEEP=pkg/client/clientset/versioned/fake
+cp "${SCRIPT_ROOT}/${KEEP}/tracker.go" "${SCRIPT_ROOT}/${KEEP}/tracker_test.go" "${OUTPUT_BASE}/${MODULE}/${KEEP}/"
+
+rm -rf "${SCRIPT_ROOT}/pkg/client"
+cp -r "${OUTPUT_BASE}/${MODULE}/pkg/client" "${SCRIPT_ROOT}/pkg/client"
@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	"k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -28,9 +29,16 @@ import (
 	// +kubebuilder:scaffold:imports
 
 	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	rayv2 "github.com/kubeflow/ray-operator/api/v2"
 	"github.com/kubeflow/ray-operator/controllers"
+	"github.com/kubeflow/ray-operator/pkg/autoscaler"
 	"github.com/kubeflow/ray-operator/pkg/composer"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+	"github.com/kubeflow/ray-operator/pkg/prober"
+	"github.com/kubeflow/ray-operator/pkg/restmapper"
+	"github.com/kubeflow/ray-operator/pkg/serve"
 	"github.com/kubeflow/ray-operator/pkg/validator"
+	"github.com/kubeflow/ray-operator/pkg/webhook"
 )
 
 var (
@@ -40,15 +48,41 @@ var (
 
 func init() {
 	_ = rayv1.AddToScheme(k8sScheme)
+	_ = rayv2.AddToScheme(k8sScheme)
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var enableWebhook bool
+	var webhookCertDir string
+	var webhookPort int
+	var autoscalerTimeout time.Duration
+	var serveTimeout time.Duration
+	var enableProber bool
+	var headProbeGCSPort int
+	var headProbeTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableWebhook, "enable-webhook", false,
+		"Enable the mutating and validating admission webhooks and the conversion webhook for Ray. "+
+			"The serving certificate must be in --webhook-cert-dir. It is required if Ray v2 is served.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "/tmp/k8s-webhook-server/serving-certs",
+		"The directory that contains the server key and certificate (tls.key and tls.crt) for the webhook server.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server serves at.")
+	flag.DurationVar(&autoscalerTimeout, "autoscaler-timeout", 5*time.Second,
+		"The timeout of the requests to the Ray dashboard.")
+	flag.DurationVar(&serveTimeout, "serve-timeout", 5*time.Second,
+		"The timeout of the requests to deploy the serve config and to get the serve applications.")
+	flag.BoolVar(&enableProber, "enable-prober", true,
+		"Enable probing the head service and getting the nodes registered with the head. "+
+			"The manager must be able to resolve and reach the head services, thus disable it when the manager runs out of the cluster.")
+	flag.IntVar(&headProbeGCSPort, "head-probe-gcs-port", consts.DefaultGCSPort,
+		"The GCS/Redis port of the head which is probed to check the head is reachable.")
+	flag.DurationVar(&headProbeTimeout, "head-probe-timeout", time.Second,
+		"The timeout of the connections to probe the head.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.Logger(true))
@@ -57,6 +91,10 @@ func main() {
 		Scheme:             k8sScheme,
 		MetricsBindAddress: metricsAddr,
 		LeaderElection:     enableLeaderElection,
+		Port:               webhookPort,
+		// The mappings are reloaded when a kind is not found, e.g. the PodGroup whose
+		// CRD is installed with the gang scheduler after the manager starts.
+		MapperProvider: restmapper.NewLazy,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -73,16 +111,62 @@ func main() {
 		ctrl.Log.WithName(validator.ValidatorName),
 	)
 
-	if err := (&controllers.RayReconciler{
+	autoscaler := autoscaler.New(
+		mgr.GetEventRecorderFor(autoscaler.AutoscalerName),
+		ctrl.Log.WithName(autoscaler.AutoscalerName),
+		autoscaler.NewHTTPDemandSource(autoscalerTimeout),
+	)
+	rayReconciler := &controllers.RayReconciler{
 		Client:        mgr.GetClient(),
 		EventRecorder: mgr.GetEventRecorderFor(controllers.ControllerName),
 		Composer:      composer,
 		Validator:     validator,
+		Autoscaler:    autoscaler,
 		Log:           ctrl.Log.WithName(controllers.ControllerName).WithName("Ray"),
-	}).SetupWithManager(mgr); err != nil {
+	}
+	if enableProber {
+		rayReconciler.Prober = prober.NewTCPProber(headProbeGCSPort, headProbeTimeout)
+		rayReconciler.NodeSource = prober.NewHTTPNodeSource(headProbeTimeout)
+	}
+	if err := rayReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Ray")
 		os.Exit(1)
 	}
+	if err := (&controllers.RayJobReconciler{
+		Client:        mgr.GetClient(),
+		EventRecorder: mgr.GetEventRecorderFor(controllers.ControllerName),
+		Composer:      composer,
+		Validator:     validator,
+		Log:           ctrl.Log.WithName(controllers.ControllerName).WithName("RayJob"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RayJob")
+		os.Exit(1)
+	}
+	if err := (&controllers.RayServiceReconciler{
+		Client:        mgr.GetClient(),
+		EventRecorder: mgr.GetEventRecorderFor(controllers.ControllerName),
+		Composer:      composer,
+		Validator:     validator,
+		Serve:         serve.NewHTTPClient(serveTimeout),
+		Log:           ctrl.Log.WithName(controllers.ControllerName).WithName("RayService"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RayService")
+		os.Exit(1)
+	}
+	if enableWebhook {
+		mgr.GetWebhookServer().CertDir = webhookCertDir
+		if err := (&webhook.Mutating{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Mutating")
+			os.Exit(1)
+		}
+		if err := (&webhook.Validating{
+			Validator: validator,
+			Client:    mgr.GetClient(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Validating")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
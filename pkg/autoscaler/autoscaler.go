@@ -0,0 +1,314 @@
+package autoscaler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+const (
+	AutoscalerName = "ray-operator-autoscaler"
+
+	// SyncPeriod is the period the demand of the autoscaling Ray is checked.
+	SyncPeriod = 30 * time.Second
+
+	defaultMinReplicas        = 1
+	defaultUpscalingSpeed     = 100
+	defaultIdleTimeoutSeconds = 300
+
+	// The resource names used by Ray.
+	resourceCPU    = "CPU"
+	resourceGPU    = "GPU"
+	resourceMemory = "memory"
+
+	resourceNvidiaGPU corev1.ResourceName = "nvidia.com/gpu"
+
+	// The ray start parameters which set the resources of a node.
+	paramNumCPUs = "num-cpus"
+	paramNumGPUs = "num-gpus"
+)
+
+// Demand is the resource demand of the Ray cluster.
+type Demand struct {
+	// PendingResources are the resource bundles which cannot be scheduled in the cluster.
+	PendingResources []map[string]float64
+	// UsedResources are the resources in use in the cluster.
+	UsedResources map[string]float64
+	// TotalResources are the resources provided by the nodes in the cluster.
+	TotalResources map[string]float64
+}
+
+// DemandSource gets the resource demand of the Ray cluster.
+type DemandSource interface {
+	GetDemand(ray *rayv1.Ray) (*Demand, error)
+}
+
+// Interface scales the workers of the Ray.
+type Interface interface {
+	// Scale decides the number of the workers and records the decision
+	// in the autoscaler status of the Ray.
+	Scale(ray *rayv1.Ray) error
+}
+
+// Autoscaler is the default implementation for the Interface.
+type Autoscaler struct {
+	record.EventRecorder
+	Log    logr.Logger
+	Source DemandSource
+
+	now func() time.Time
+}
+
+// New returns a new Autoscaler.
+func New(recorder record.EventRecorder, log logr.Logger, source DemandSource) Interface {
+	return &Autoscaler{
+		EventRecorder: recorder,
+		Log:           log,
+		Source:        source,
+		now:           time.Now,
+	}
+}
+
+// Scale decides the number of the workers and records the decision
+// in the autoscaler status of the Ray.
+func (a Autoscaler) Scale(ray *rayv1.Ray) error {
+	spec := ray.Spec.Autoscaling
+	if spec == nil {
+		ray.Status.Autoscaler = nil
+		return nil
+	}
+	status := ray.Status.Autoscaler
+	if status == nil {
+		status = &rayv1.AutoscalerStatus{
+			DesiredReplicas: initialReplicas(ray),
+		}
+		ray.Status.Autoscaler = status
+	}
+
+	demand, err := a.Source.GetDemand(ray)
+	if err != nil {
+		// Keep the current workers if the head is not reachable.
+		a.Log.V(1).Info("Failed to get the demand", "namespace", ray.Namespace,
+			"name", ray.Name, "error", err.Error())
+		return err
+	}
+
+	old := status.DesiredReplicas
+	now := metav1.NewTime(a.now())
+	desired, message := recommend(spec, status, demand,
+		workerCapacity(ray.Spec.Worker.Template), headCapacity(ray), now)
+	status.DesiredReplicas = desired
+	status.PendingResources = formatResources(sumResources(demand.PendingResources))
+	if desired == old {
+		return nil
+	}
+
+	status.LastScaleTime = &now
+	status.Message = message
+	reason := consts.ReasonScaleUp
+	if desired < old {
+		reason = consts.ReasonScaleDown
+	}
+	a.Log.V(1).Info("Scaling the workers", "namespace", ray.Namespace, "name", ray.Name,
+		"from", old, "to", desired, "message", message)
+	a.Event(ray, consts.EventNormal, reason,
+		fmt.Sprintf("Scale the workers from %d to %d: %s", old, desired, message))
+	return nil
+}
+
+// recommend returns the desired number of the workers according to the demand.
+// The idle time of the workers is tracked in the status.
+func recommend(spec *rayv1.AutoscalingSpec, status *rayv1.AutoscalerStatus,
+	demand *Demand, capacity, head map[string]float64, now metav1.Time) (int32, string) {
+	minReplicas := int32(defaultMinReplicas)
+	if spec.MinReplicas != nil {
+		minReplicas = *spec.MinReplicas
+	}
+	speed := int32(defaultUpscalingSpeed)
+	if spec.UpscalingSpeed != nil {
+		speed = *spec.UpscalingSpeed
+	}
+	idleTimeout := time.Duration(defaultIdleTimeoutSeconds) * time.Second
+	if spec.IdleTimeoutSeconds != nil {
+		idleTimeout = time.Duration(*spec.IdleTimeoutSeconds) * time.Second
+	}
+
+	current := status.DesiredReplicas
+	desired := current
+	message := ""
+	if needed := workersFor(demand.PendingResources, capacity); needed > 0 {
+		status.IdleSince = nil
+		step := int32(math.Ceil(float64(current) * float64(speed) / 100))
+		if step < 1 {
+			step = 1
+		}
+		if needed > step {
+			needed = step
+		}
+		desired = current + needed
+		message = fmt.Sprintf("%d workers are needed by the pending demand", needed)
+	} else if idle := idleWorkers(demand, capacity, head, current); idle > 0 {
+		if status.IdleSince == nil {
+			status.IdleSince = &now
+		} else if now.Sub(status.IdleSince.Time) >= idleTimeout {
+			status.IdleSince = nil
+			desired = current - idle
+			message = fmt.Sprintf("%d workers are idle for %s", idle, idleTimeout)
+		}
+	} else {
+		status.IdleSince = nil
+	}
+
+	if desired > spec.MaxReplicas {
+		desired = spec.MaxReplicas
+	}
+	if desired < minReplicas {
+		desired = minReplicas
+	}
+	if message == "" && desired != current {
+		message = fmt.Sprintf("the workers should be in [%d, %d]", minReplicas, spec.MaxReplicas)
+	}
+	return desired, message
+}
+
+// workersFor returns the number of the workers which provide the pending resources.
+// If the resources of the worker are not specified, every bundle needs one worker.
+func workersFor(bundles []map[string]float64, capacity map[string]float64) int32 {
+	if len(capacity) == 0 {
+		return int32(len(bundles))
+	}
+	needed := 0.0
+	for name, amount := range sumResources(bundles) {
+		c, ok := capacity[name]
+		if !ok || c <= 0 || amount <= 0 {
+			continue
+		}
+		needed = math.Max(needed, math.Ceil(amount/c))
+	}
+	return int32(needed)
+}
+
+// idleWorkers returns the number of the workers which could be removed without
+// affecting the resources in use. The total resources of the cluster include the
+// head, thus the resources of the head are subtracted and the workers never provide
+// more than their capacity. The resources in use may be on the head, so the result
+// never overcounts the idle workers. If the resources of the worker are not specified,
+// the workers are idle only when no CPU or GPU is in use.
+func idleWorkers(demand *Demand, capacity, head map[string]float64, current int32) int32 {
+	if len(capacity) == 0 {
+		if demand.UsedResources[resourceCPU] > 0 || demand.UsedResources[resourceGPU] > 0 {
+			return 0
+		}
+		return current
+	}
+	idle := int32(-1)
+	for name, c := range capacity {
+		if c <= 0 {
+			continue
+		}
+		total := math.Min(demand.TotalResources[name]-head[name], float64(current)*c)
+		n := int32(math.Floor((total - demand.UsedResources[name]) / c))
+		if idle < 0 || n < idle {
+			idle = n
+		}
+	}
+	if idle < 0 {
+		return 0
+	}
+	return idle
+}
+
+// workerCapacity returns the resources provided by a worker in Ray resource names.
+func workerCapacity(template *corev1.PodTemplateSpec) map[string]float64 {
+	return containerCapacity(template, consts.ContainerRayWorker)
+}
+
+// headCapacity returns the resources provided by the head in Ray resource names.
+// The CPUs and GPUs set in the ray start parameters override the container resources.
+func headCapacity(ray *rayv1.Ray) map[string]float64 {
+	var template *corev1.PodTemplateSpec
+	if ray.Spec.Head != nil {
+		template = ray.Spec.Head.Template
+	}
+	capacity := containerCapacity(template, consts.ContainerRayHead)
+	for param, name := range map[string]string{
+		paramNumCPUs: resourceCPU,
+		paramNumGPUs: resourceGPU,
+	} {
+		v, ok := ray.Spec.RayStartParams[param]
+		if !ok {
+			continue
+		}
+		if amount, err := strconv.ParseFloat(v, 64); err == nil {
+			capacity[name] = amount
+		}
+	}
+	return capacity
+}
+
+// containerCapacity returns the resources provided by the container named name.
+func containerCapacity(template *corev1.PodTemplateSpec, name string) map[string]float64 {
+	capacity := map[string]float64{}
+	if template == nil {
+		return capacity
+	}
+	for _, c := range template.Spec.Containers {
+		if c.Name != name {
+			continue
+		}
+		resources := c.Resources.Requests
+		if len(resources) == 0 {
+			resources = c.Resources.Limits
+		}
+		if cpu, ok := resources[corev1.ResourceCPU]; ok {
+			capacity[resourceCPU] = float64(cpu.MilliValue()) / 1000
+		}
+		if memory, ok := resources[corev1.ResourceMemory]; ok {
+			capacity[resourceMemory] = float64(memory.Value())
+		}
+		if gpu, ok := c.Resources.Limits[resourceNvidiaGPU]; ok {
+			capacity[resourceGPU] = float64(gpu.Value())
+		}
+	}
+	return capacity
+}
+
+func initialReplicas(ray *rayv1.Ray) int32 {
+	if ray.Spec.Worker.Replicas != nil {
+		return *ray.Spec.Worker.Replicas
+	}
+	if ray.Spec.Autoscaling.MinReplicas != nil {
+		return *ray.Spec.Autoscaling.MinReplicas
+	}
+	return defaultMinReplicas
+}
+
+func sumResources(bundles []map[string]float64) map[string]float64 {
+	sum := map[string]float64{}
+	for _, bundle := range bundles {
+		for name, amount := range bundle {
+			sum[name] += amount
+		}
+	}
+	return sum
+}
+
+func formatResources(resources map[string]float64) map[string]string {
+	if len(resources) == 0 {
+		return nil
+	}
+	formatted := make(map[string]string, len(resources))
+	for name, amount := range resources {
+		formatted[name] = strconv.FormatFloat(amount, 'f', -1, 64)
+	}
+	return formatted
+}
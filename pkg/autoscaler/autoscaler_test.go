@@ -0,0 +1,262 @@
+package autoscaler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+type fakeDemandSource struct {
+	demand *Demand
+	err    error
+}
+
+func (f *fakeDemandSource) GetDemand(ray *rayv1.Ray) (*Demand, error) {
+	return f.demand, f.err
+}
+
+func newTestRay(replicas, maxReplicas int32) *rayv1.Ray {
+	return &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: rayv1.RaySpec{
+			Worker: rayv1.ReplicaSpec{
+				Replicas: &replicas,
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name: consts.ContainerRayWorker,
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU: resource.MustParse("2"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Autoscaling: &rayv1.AutoscalingSpec{
+				MaxReplicas: maxReplicas,
+			},
+		},
+	}
+}
+
+func newTestAutoscaler(source DemandSource, now *time.Time) *Autoscaler {
+	return &Autoscaler{
+		EventRecorder: record.NewFakeRecorder(10),
+		Log:           logf.NullLogger{},
+		Source:        source,
+		now: func() time.Time {
+			return *now
+		},
+	}
+}
+
+func TestScaleUp(t *testing.T) {
+	now := time.Now()
+	source := &fakeDemandSource{
+		demand: &Demand{
+			PendingResources: []map[string]float64{
+				{"CPU": 2}, {"CPU": 2}, {"CPU": 1},
+			},
+		},
+	}
+	a := newTestAutoscaler(source, &now)
+
+	ray := newTestRay(3, 10)
+	if err := a.Scale(ray); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 5 CPUs are pending and a worker provides 2 CPUs.
+	if got := ray.Status.Autoscaler.DesiredReplicas; got != 6 {
+		t.Errorf("expected 6 workers, got %d", got)
+	}
+	if ray.Status.Autoscaler.LastScaleTime == nil {
+		t.Errorf("expected the last scale time to be set")
+	}
+	if got := ray.Status.Autoscaler.PendingResources["CPU"]; got != "5" {
+		t.Errorf("expected 5 pending CPUs, got %s", got)
+	}
+}
+
+func TestScaleUpLimitedBySpeedAndMax(t *testing.T) {
+	now := time.Now()
+	source := &fakeDemandSource{
+		demand: &Demand{
+			PendingResources: []map[string]float64{
+				{"CPU": 20},
+			},
+		},
+	}
+	a := newTestAutoscaler(source, &now)
+
+	ray := newTestRay(2, 10)
+	speed := int32(50)
+	ray.Spec.Autoscaling.UpscalingSpeed = &speed
+	if err := a.Scale(ray); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ray.Status.Autoscaler.DesiredReplicas; got != 3 {
+		t.Errorf("expected 3 workers, got %d", got)
+	}
+
+	ray.Spec.Autoscaling.UpscalingSpeed = nil
+	for i := 0; i < 5; i++ {
+		if err := a.Scale(ray); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := ray.Status.Autoscaler.DesiredReplicas; got != 10 {
+		t.Errorf("expected 10 workers, got %d", got)
+	}
+}
+
+func TestScaleDownAfterIdleTimeout(t *testing.T) {
+	now := time.Now()
+	source := &fakeDemandSource{
+		demand: &Demand{
+			UsedResources:  map[string]float64{"CPU": 2},
+			TotalResources: map[string]float64{"CPU": 8},
+		},
+	}
+	a := newTestAutoscaler(source, &now)
+
+	ray := newTestRay(4, 10)
+	timeout := int32(60)
+	ray.Spec.Autoscaling.IdleTimeoutSeconds = &timeout
+	if err := a.Scale(ray); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := ray.Status.Autoscaler.DesiredReplicas; got != 4 {
+		t.Errorf("expected 4 workers before the idle timeout, got %d", got)
+	}
+	if ray.Status.Autoscaler.IdleSince == nil {
+		t.Fatalf("expected the idle time to be set")
+	}
+
+	now = now.Add(61 * time.Second)
+	if err := a.Scale(ray); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 6 CPUs are idle, thus 3 workers could be removed.
+	if got := ray.Status.Autoscaler.DesiredReplicas; got != 1 {
+		t.Errorf("expected 1 worker after the idle timeout, got %d", got)
+	}
+}
+
+func TestScaleKeepsReplicasOnError(t *testing.T) {
+	now := time.Now()
+	a := newTestAutoscaler(&fakeDemandSource{err: errors.New("unreachable")}, &now)
+
+	ray := newTestRay(3, 10)
+	if err := a.Scale(ray); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if got := ray.Status.Autoscaler.DesiredReplicas; got != 3 {
+		t.Errorf("expected 3 workers, got %d", got)
+	}
+}
+
+func TestScaleDownExcludesHeadResources(t *testing.T) {
+	now := time.Now()
+	source := &fakeDemandSource{
+		demand: &Demand{
+			UsedResources:  map[string]float64{"CPU": 2},
+			TotalResources: map[string]float64{"CPU": 12},
+		},
+	}
+	a := newTestAutoscaler(source, &now)
+
+	ray := newTestRay(4, 10)
+	ray.Spec.Head = &rayv1.HeadSpec{
+		ReplicaSpec: rayv1.ReplicaSpec{
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: consts.ContainerRayHead,
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU: resource.MustParse("4"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	timeout := int32(0)
+	ray.Spec.Autoscaling.IdleTimeoutSeconds = &timeout
+	for i := 0; i < 2; i++ {
+		if err := a.Scale(ray); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// The head provides 4 of the 12 CPUs, thus 6 CPUs of the workers are idle.
+	if got := ray.Status.Autoscaler.DesiredReplicas; got != 1 {
+		t.Errorf("expected 1 worker, got %d", got)
+	}
+}
+
+func TestIdleWorkers(t *testing.T) {
+	capacity := map[string]float64{"CPU": 2}
+	tests := []struct {
+		name    string
+		demand  *Demand
+		head    map[string]float64
+		current int32
+		want    int32
+	}{
+		{
+			name: "head resources subtracted",
+			demand: &Demand{
+				UsedResources:  map[string]float64{"CPU": 2},
+				TotalResources: map[string]float64{"CPU": 10},
+			},
+			head:    map[string]float64{"CPU": 4},
+			current: 3,
+			want:    2,
+		},
+		{
+			name: "unknown head resources limited by the workers",
+			demand: &Demand{
+				UsedResources:  map[string]float64{"CPU": 2},
+				TotalResources: map[string]float64{"CPU": 16},
+			},
+			current: 3,
+			want:    2,
+		},
+		{
+			name: "all in use",
+			demand: &Demand{
+				UsedResources:  map[string]float64{"CPU": 10},
+				TotalResources: map[string]float64{"CPU": 10},
+			},
+			head:    map[string]float64{"CPU": 4},
+			current: 3,
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := idleWorkers(tt.demand, capacity, tt.head, tt.current); got != tt.want {
+				t.Errorf("expected %d idle workers, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package autoscaler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+)
+
+const (
+	clusterStatusPath = "/api/cluster_status"
+)
+
+// HTTPDemandSource gets the demand from the dashboard of the head through the head service.
+// The dashboard port is resolved from the specification of every Ray.
+type HTTPDemandSource struct {
+	Client *http.Client
+}
+
+// NewHTTPDemandSource returns a new HTTPDemandSource.
+func NewHTTPDemandSource(timeout time.Duration) DemandSource {
+	return &HTTPDemandSource{
+		Client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// clusterStatus is the response of the cluster status API of the dashboard.
+type clusterStatus struct {
+	Result bool   `json:"result"`
+	Msg    string `json:"msg"`
+	Data   struct {
+		ClusterStatus struct {
+			LoadMetricsReport struct {
+				// Usage maps the resource name to [used, total].
+				Usage map[string][]float64 `json:"usage"`
+				// ResourceDemand is the list of [bundle, count].
+				ResourceDemand [][]json.RawMessage `json:"resourceDemand"`
+				// RequestDemand is the list of [bundle, count] requested by request_resources().
+				RequestDemand [][]json.RawMessage `json:"requestDemand"`
+			} `json:"loadMetricsReport"`
+		} `json:"clusterStatus"`
+	} `json:"data"`
+}
+
+// GetDemand gets the demand from the dashboard of the head.
+func (s HTTPDemandSource) GetDemand(ray *rayv1.Ray) (*Demand, error) {
+	url := fmt.Sprintf("http://%s.%s.svc:%d%s", composer.GetHeadName(ray.Name),
+		ray.Namespace, composer.GetDashboardPort(&ray.Spec), clusterStatusPath)
+	resp, err := s.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the cluster status from %s: %s", url, resp.Status)
+	}
+
+	status := &clusterStatus{}
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return nil, err
+	}
+	if !status.Result {
+		return nil, fmt.Errorf("failed to get the cluster status from %s: %s", url, status.Msg)
+	}
+	return parseClusterStatus(status)
+}
+
+func parseClusterStatus(status *clusterStatus) (*Demand, error) {
+	report := status.Data.ClusterStatus.LoadMetricsReport
+	demand := &Demand{
+		UsedResources:  map[string]float64{},
+		TotalResources: map[string]float64{},
+	}
+	for name, usage := range report.Usage {
+		if len(usage) != 2 {
+			return nil, fmt.Errorf("unexpected usage of the resource %s: %v", name, usage)
+		}
+		demand.UsedResources[name] = usage[0]
+		demand.TotalResources[name] = usage[1]
+	}
+
+	for _, bundles := range [][][]json.RawMessage{report.ResourceDemand, report.RequestDemand} {
+		for _, entry := range bundles {
+			if len(entry) != 2 {
+				return nil, fmt.Errorf("unexpected resource demand: %v", entry)
+			}
+			bundle := map[string]float64{}
+			if err := json.Unmarshal(entry[0], &bundle); err != nil {
+				return nil, err
+			}
+			count := 0
+			if err := json.Unmarshal(entry[1], &count); err != nil {
+				return nil, err
+			}
+			for i := 0; i < count; i++ {
+				demand.PendingResources = append(demand.PendingResources, bundle)
+			}
+		}
+	}
+	return demand, nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Kubeflow community.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	rayv2 "github.com/kubeflow/ray-operator/api/v2"
+)
+
+func TestNewClientset(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "test", Namespace: "default"}
+	cases := []struct {
+		name string
+		obj  runtime.Object
+		get  func(c *Clientset) error
+	}{
+		{"v1 ray", &rayv1.Ray{ObjectMeta: meta}, func(c *Clientset) error {
+			_, err := c.RayV1().Rays("default").Get("test", metav1.GetOptions{})
+			return err
+		}},
+		{"v2 ray", &rayv2.Ray{ObjectMeta: meta}, func(c *Clientset) error {
+			_, err := c.RayV2().Rays("default").Get("test", metav1.GetOptions{})
+			return err
+		}},
+		{"v1 rayjob", &rayv1.RayJob{ObjectMeta: meta}, func(c *Clientset) error {
+			_, err := c.RayV1().RayJobs("default").Get("test", metav1.GetOptions{})
+			return err
+		}},
+		{"v1 rayservice", &rayv1.RayService{ObjectMeta: meta}, func(c *Clientset) error {
+			_, err := c.RayV1().RayServices("default").Get("test", metav1.GetOptions{})
+			return err
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.get(NewClientset(c.obj)); err != nil {
+				t.Errorf("expected the seeded object, got %v", err)
+			}
+		})
+	}
+}
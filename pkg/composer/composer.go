@@ -3,7 +3,10 @@ package composer
 import (
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 
@@ -14,11 +17,26 @@ const (
 	ComposerName = "ray-operator-composer"
 )
 
-// Interface composes the desired specification for Head and Worker.
+// Interface composes the desired specification for Head and Worker, for the
+// Ray cluster and the submitter of the RayJob, and for the Ray cluster and the
+// stable service of the RayService.
 type Interface interface {
 	DesiredHead(ray *rayv1.Ray) (*appsv1.Deployment, error)
-	DesiredWorker(ray *rayv1.Ray) (*appsv1.Deployment, error)
+	DesiredHeadStatefulSet(ray *rayv1.Ray) (*appsv1.StatefulSet, error)
+	DesiredHeadPod(ray *rayv1.Ray) (*corev1.Pod, error)
+	DesiredWorkers(ray *rayv1.Ray) ([]*appsv1.Deployment, error)
 	DesiredHeadService(ray *rayv1.Ray) (*corev1.Service, error)
+	DesiredHeadGoverningService(ray *rayv1.Ray) (*corev1.Service, error)
+	DesiredHeadIngress(ray *rayv1.Ray) (*networkingv1beta1.Ingress, error)
+	DesiredWorkerService(ray *rayv1.Ray) (*corev1.Service, error)
+	DesiredPodGroup(ray *rayv1.Ray) (*unstructured.Unstructured, error)
+	DesiredPreDeleteHook(ray *rayv1.Ray) (*batchv1.Job, error)
+
+	DesiredRayForJob(job *rayv1.RayJob) (*rayv1.Ray, error)
+	DesiredSubmitter(job *rayv1.RayJob, ray *rayv1.Ray) (*batchv1.Job, error)
+
+	DesiredRayForService(svc *rayv1.RayService) (*rayv1.Ray, error)
+	DesiredServeService(svc *rayv1.RayService, ray *rayv1.Ray) (*corev1.Service, error)
 }
 
 // Composer is the default implementation for the Interface.
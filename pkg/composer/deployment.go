@@ -1,6 +1,7 @@
 package composer
 
 import (
+	"encoding/json"
 	"fmt"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -12,63 +13,157 @@ import (
 	"github.com/kubeflow/ray-operator/pkg/consts"
 )
 
-// DesiredHead gets the desired specification of the Head.
+// DesiredHead gets the desired specification of the Head when it runs in a Deployment.
 func (c Composer) DesiredHead(ray *rayv1.Ray) (*appsv1.Deployment, error) {
 	deploymentLabels := ray.Labels
-	headName := getHeadName(ray.Name)
-
-	podLabels := getHeadPodLabels(ray.Name)
-	for k, v := range ray.Labels {
-		podLabels[k] = v
-	}
-
-	template := ray.Spec.Head.Template.DeepCopy()
-	template.Labels = podLabels
-	for i := range template.Spec.Containers {
-		template.Spec.Containers[i].Env = append(template.Spec.Containers[i].Env,
-			corev1.EnvVar{
-				Name: consts.EnvNodeIP,
-				ValueFrom: &corev1.EnvVarSource{
-					FieldRef: &corev1.ObjectFieldSelector{
-						FieldPath: consts.FieldPathPodIP,
-					},
-				},
-			})
-	}
+	template := desiredHeadTemplate(ray)
 
 	deploy := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      headName,
+			Name:      GetHeadName(ray.Name),
 			Namespace: ray.Namespace,
 			Labels:    deploymentLabels,
 		},
 		Spec: appsv1.DeploymentSpec{
 			Selector: &metav1.LabelSelector{
-				MatchLabels: podLabels,
+				MatchLabels: getHeadSelectorLabels(ray),
 			},
 			Replicas: ray.Spec.Head.Replicas,
 			Template: *template,
 		},
 	}
+	// The replicas are compared by the controller directly, thus only the template is hashed.
+	if err := setSpecHash(&deploy.ObjectMeta, deploy.Spec.Template); err != nil {
+		return nil, err
+	}
 	if err := controllerutil.SetControllerReference(ray, deploy, c.scheme); err != nil {
 		return nil, err
 	}
 	return deploy, nil
 }
 
-// DesiredWorker gets the desired specificatio of the Worker.
-func (c Composer) DesiredWorker(ray *rayv1.Ray) (*appsv1.Deployment, error) {
-	deploymentLabels := ray.Labels
-	headName := getHeadName(ray.Name)
-	workerName := getWorkerName(ray.Name)
+// DesiredHeadStatefulSet gets the desired specification of the Head when it runs in
+// a StatefulSet. The head pod is named {ray.name}-head-0 and is governed by the headless
+// service {ray.name}-head-headless.
+func (c Composer) DesiredHeadStatefulSet(ray *rayv1.Ray) (*appsv1.StatefulSet, error) {
+	template := desiredHeadTemplate(ray)
+
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetHeadName(ray.Name),
+			Namespace: ray.Namespace,
+			Labels:    ray.Labels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: getHeadSelectorLabels(ray),
+			},
+			ServiceName: GetHeadGoverningServiceName(ray.Name),
+			Replicas:    ray.Spec.Head.Replicas,
+			Template:    *template,
+		},
+	}
+	// The replicas are compared by the controller directly, thus only the template is hashed.
+	if err := setSpecHash(&sts.ObjectMeta, sts.Spec.Template); err != nil {
+		return nil, err
+	}
+	if err := controllerutil.SetControllerReference(ray, sts, c.scheme); err != nil {
+		return nil, err
+	}
+	return sts, nil
+}
+
+// DesiredHeadPod gets the desired specification of the Head when it runs in a bare
+// Pod named {ray.name}-head. The pod is recreated by the controller when it is changed.
+func (c Composer) DesiredHeadPod(ray *rayv1.Ray) (*corev1.Pod, error) {
+	template := desiredHeadTemplate(ray)
+
+	pod := &corev1.Pod{
+		ObjectMeta: *template.ObjectMeta.DeepCopy(),
+		Spec:       template.Spec,
+	}
+	pod.Name = GetHeadName(ray.Name)
+	pod.Namespace = ray.Namespace
+	if err := setSpecHash(&pod.ObjectMeta, template); err != nil {
+		return nil, err
+	}
+	if err := controllerutil.SetControllerReference(ray, pod, c.scheme); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+// desiredHeadTemplate returns the pod template of the head, which is shared by all kinds
+// of the head workload.
+func desiredHeadTemplate(ray *rayv1.Ray) *corev1.PodTemplateSpec {
+	template := ray.Spec.Head.Template.DeepCopy()
+	template.Labels = getHeadSelectorLabels(ray)
+	for i := range template.Spec.Containers {
+		template.Spec.Containers[i].Env = append(template.Spec.Containers[i].Env,
+			corev1.EnvVar{
+				Name: consts.EnvNodeIP,
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: consts.FieldPathPodIP,
+					},
+				},
+			})
+	}
+	setHeadFaultTolerance(template, ray)
+	setRayStartDefaults(template, consts.ContainerRayHead, &ray.Spec, true)
+	setDefaultProbes(template, consts.ContainerRayHead, GetGCSPort(&ray.Spec), ray.Spec.Head.Probes)
+	setGangScheduling(template, ray)
+	return template
+}
+
+// DesiredWorkers gets the desired specification of the Workers, one for each worker group.
+func (c Composer) DesiredWorkers(ray *rayv1.Ray) ([]*appsv1.Deployment, error) {
+	groups := ray.Spec.GetWorkerGroups()
+	deploys := make([]*appsv1.Deployment, 0, len(groups))
+	for i := range groups {
+		deploy, err := c.desiredWorker(ray, &groups[i])
+		if err != nil {
+			return nil, err
+		}
+		deploys = append(deploys, deploy)
+	}
+	return deploys, nil
+}
+
+// desiredWorker gets the desired specification of the Worker in the group.
+func (c Composer) desiredWorker(ray *rayv1.Ray, group *rayv1.WorkerGroupSpec) (*appsv1.Deployment, error) {
+	// The deployments of the workers are listed by the label of the Ray.
+	deploymentLabels := map[string]string{}
+	for k, v := range ray.Labels {
+		deploymentLabels[k] = v
+	}
+	deploymentLabels[consts.LabelRay] = ray.Name
+	workerName := GetWorkerName(ray.Name, group.Name)
 
-	podLabels := getWorkerPodLabels(ray.Name)
+	podLabels := GetWorkerPodLabels(ray.Name, group.Name)
 	for k, v := range ray.Labels {
 		podLabels[k] = v
 	}
 
-	template := ray.Spec.Worker.Template.DeepCopy()
-	template.Labels = podLabels
+	resources := group.RayResources
+	if resources == nil {
+		resources = map[string]int64{}
+	}
+	resourcesJSON, err := json.Marshal(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	template := group.Template.DeepCopy()
+	// The group label is not a part of the selector since the selector
+	// of the existing deployment is immutable.
+	template.Labels = map[string]string{
+		consts.LabelRayWorkerGroup: group.Name,
+		consts.LabelRayNodeType:    consts.RayNodeTypeWorker,
+	}
+	for k, v := range podLabels {
+		template.Labels[k] = v
+	}
 	for i := range template.Spec.Containers {
 		template.Spec.Containers[i].Env = append(template.Spec.Containers[i].Env,
 			corev1.EnvVar{
@@ -78,12 +173,22 @@ func (c Composer) DesiredWorker(ray *rayv1.Ray) (*appsv1.Deployment, error) {
 						FieldPath: consts.FieldPathPodIP,
 					},
 				},
-			},
-			corev1.EnvVar{
-				Name:  consts.EnvRayHeadService,
-				Value: headName,
 			})
+		template.Spec.Containers[i].Env = append(template.Spec.Containers[i].Env,
+			discoveryEnv(ray)...)
 	}
+	appendRayContainerEnv(template, consts.ContainerRayWorker, []corev1.EnvVar{
+		{
+			Name:  consts.EnvWorkerResources,
+			Value: string(resourcesJSON),
+		},
+	})
+	setWaitForHead(template, ray)
+	setWorkerFaultTolerance(template, ray)
+	setRayStartDefaults(template, consts.ContainerRayWorker, &ray.Spec, false)
+	setDefaultProbes(template, consts.ContainerRayWorker,
+		rayStartPort(&ray.Spec, consts.PortNameNodeManager), group.Probes)
+	setGangScheduling(template, ray)
 
 	deploy := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -95,34 +200,130 @@ func (c Composer) DesiredWorker(ray *rayv1.Ray) (*appsv1.Deployment, error) {
 			Selector: &metav1.LabelSelector{
 				MatchLabels: podLabels,
 			},
-			Replicas: ray.Spec.Worker.Replicas,
+			Replicas: desiredWorkerReplicas(ray, group),
 			Template: *template,
 		},
 	}
+	// The replicas are compared by the controller directly, thus only the template is hashed.
+	if err := setSpecHash(&deploy.ObjectMeta, deploy.Spec.Template); err != nil {
+		return nil, err
+	}
+	if isExternalReplicas(ray, group) {
+		deploy.Annotations[consts.AnnotationExternalReplicas] = "true"
+	}
 	if err := controllerutil.SetControllerReference(ray, deploy, c.scheme); err != nil {
 		return nil, err
 	}
 	return deploy, nil
 }
 
+// isExternalReplicas returns true if the replicas of the worker group are managed by
+// another controller, e.g. a HorizontalPodAutoscaler targeting the worker deployment.
+// The replicas are then set only when the deployment is created. The autoscaler of the
+// Ray takes precedence over the annotation.
+func isExternalReplicas(ray *rayv1.Ray, group *rayv1.WorkerGroupSpec) bool {
+	if ray.Spec.Autoscaling != nil && group.Name == rayv1.DefaultWorkerGroupName {
+		return false
+	}
+	return ray.Annotations[consts.AnnotationExternalReplicas] == "true"
+}
+
+// getHeadSelectorLabels returns the labels which select the head pods, including the
+// labels of the Ray.
+func getHeadSelectorLabels(ray *rayv1.Ray) map[string]string {
+	podLabels := getHeadPodLabels(ray.Name)
+	for k, v := range ray.Labels {
+		podLabels[k] = v
+	}
+	return podLabels
+}
+
 func getHeadPodLabels(rayName string) map[string]string {
 	return map[string]string{
-		consts.LabelRayHead: getHeadName(rayName),
+		consts.LabelRayHead: GetHeadName(rayName),
 		consts.LabelRay:     rayName,
 	}
 }
 
-func getWorkerPodLabels(rayName string) map[string]string {
+// desiredWorkerReplicas returns the replicas decided by the autoscaler if the
+// autoscaling is enabled, or the replicas in the specification.
+func desiredWorkerReplicas(ray *rayv1.Ray, group *rayv1.WorkerGroupSpec) *int32 {
+	if ray.Spec.Autoscaling != nil && ray.Status.Autoscaler != nil &&
+		group.Name == rayv1.DefaultWorkerGroupName {
+		replicas := ray.Status.Autoscaler.DesiredReplicas
+		return &replicas
+	}
+	return group.Replicas
+}
+
+// discoveryEnv returns the environment variables which locate the head and the other
+// workers of the Ray.
+func discoveryEnv(ray *rayv1.Ray) []corev1.EnvVar {
+	headFQDN := GetServiceFQDN(GetHeadName(ray.Name), ray.Namespace)
+	return []corev1.EnvVar{
+		{
+			Name:  consts.EnvRayHeadService,
+			Value: GetHeadName(ray.Name),
+		},
+		{
+			Name:  consts.EnvRayHeadServiceFQDN,
+			Value: headFQDN,
+		},
+		{
+			Name:  consts.EnvRayGCSAddress,
+			Value: fmt.Sprintf("%s:%d", headFQDN, GetGCSPort(&ray.Spec)),
+		},
+		{
+			Name:  consts.EnvRayWorkerServiceFQDN,
+			Value: GetServiceFQDN(GetWorkerServiceName(ray.Name), ray.Namespace),
+		},
+	}
+}
+
+// setWaitForHead adds the init container which blocks the worker from starting until
+// the GCS/Redis port of the head accepts connections. It runs the image of the Ray
+// container, and is not added if the template has no Ray container or already has it.
+func setWaitForHead(template *corev1.PodTemplateSpec, ray *rayv1.Ray) {
+	for _, c := range template.Spec.InitContainers {
+		if c.Name == consts.ContainerWaitForHead {
+			return
+		}
+	}
+	for _, c := range template.Spec.Containers {
+		if c.Name != consts.ContainerRayWorker {
+			continue
+		}
+		template.Spec.InitContainers = append(template.Spec.InitContainers, corev1.Container{
+			Name:    consts.ContainerWaitForHead,
+			Image:   c.Image,
+			Command: defaultCmd,
+			Args: []string{fmt.Sprintf(
+				"until (exec 3<>/dev/tcp/${%s%%:*}/${%s##*:}) 2>/dev/null; do echo waiting for the head at $%s; sleep 2; done",
+				consts.EnvRayGCSAddress, consts.EnvRayGCSAddress, consts.EnvRayGCSAddress)},
+			Env: discoveryEnv(ray),
+		})
+		return
+	}
+}
+
+// GetWorkerPodLabels returns the labels which select the worker pods in the group.
+func GetWorkerPodLabels(rayName, groupName string) map[string]string {
 	return map[string]string{
-		consts.LabelRayWorker: getWorkerName(rayName),
+		consts.LabelRayWorker: GetWorkerName(rayName, groupName),
 		consts.LabelRay:       rayName,
 	}
 }
 
-func getHeadName(rayName string) string {
+// GetHeadName returns the name of the head workload and service.
+func GetHeadName(rayName string) string {
 	return fmt.Sprintf("%s-head", rayName)
 }
 
-func getWorkerName(rayName string) string {
-	return fmt.Sprintf("%s-worker", rayName)
+// GetWorkerName returns the name of the worker deployment in the group. The
+// default group keeps the name used before worker groups were introduced.
+func GetWorkerName(rayName, groupName string) string {
+	if groupName == rayv1.DefaultWorkerGroupName {
+		return fmt.Sprintf("%s-worker", rayName)
+	}
+	return fmt.Sprintf("%s-worker-%s", rayName, groupName)
 }
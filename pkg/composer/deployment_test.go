@@ -0,0 +1,262 @@
+package composer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+func newTestComposer() Interface {
+	s := runtime.NewScheme()
+	_ = scheme.AddToScheme(s)
+	_ = rayv1.AddToScheme(s)
+	return New(record.NewFakeRecorder(10), logf.NullLogger{}, s)
+}
+
+func newTestRay() *rayv1.Ray {
+	ray := &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Labels:    map[string]string{"team": "ml"},
+		},
+	}
+	ray.Default()
+	return ray
+}
+
+func findContainer(template *corev1.PodTemplateSpec, name string) *corev1.Container {
+	if template == nil {
+		return nil
+	}
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == name {
+			return &template.Spec.Containers[i]
+		}
+	}
+	return nil
+}
+
+func findEnv(container *corev1.Container, name string) *corev1.EnvVar {
+	for i := range container.Env {
+		if container.Env[i].Name == name {
+			return &container.Env[i]
+		}
+	}
+	return nil
+}
+
+func TestDesiredWorkersResourcesEnv(t *testing.T) {
+	ray := newTestRay()
+	ray.Spec.WorkerGroups = []rayv1.WorkerGroupSpec{
+		{
+			Name:         "cpu",
+			RayResources: map[string]int64{"custom": 2},
+			ReplicaSpec: rayv1.ReplicaSpec{
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "sidecar", Image: "busybox"}},
+					},
+				},
+			},
+		},
+	}
+	ray.Spec.Worker = rayv1.ReplicaSpec{}
+	ray.Default()
+
+	deploys, err := newTestComposer().DesiredWorkers(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deploys) != 1 {
+		t.Fatalf("expected 1 worker deployment, got %d", len(deploys))
+	}
+	for _, container := range deploys[0].Spec.Template.Spec.Containers {
+		env := findEnv(&container, consts.EnvWorkerResources)
+		switch container.Name {
+		case consts.ContainerRayWorker:
+			if env == nil || env.Value != `{"custom":2}` {
+				t.Errorf("expected the resources in the ray container, got %v", env)
+			}
+		default:
+			if env != nil {
+				t.Errorf("expected no resources in the container %s, got %v", container.Name, env)
+			}
+		}
+	}
+}
+
+func TestDesiredWorkersLabels(t *testing.T) {
+	ray := newTestRay()
+	deploys, err := newTestComposer().DesiredWorkers(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	labels := deploys[0].Labels
+	if labels[consts.LabelRay] != ray.Name || labels["team"] != "ml" {
+		t.Errorf("expected the labels of the Ray and the Ray label, got %v", labels)
+	}
+	// The labels of the Ray must not be modified.
+	if _, ok := ray.Labels[consts.LabelRay]; ok {
+		t.Errorf("expected the labels of the Ray not to be modified, got %v", ray.Labels)
+	}
+}
+
+func TestDesiredWorkersExternalReplicas(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotation  string
+		autoscaling bool
+		expected    bool
+	}{
+		{"not annotated", "", false, false},
+		{"annotated", "true", false, true},
+		{"annotated with autoscaling", "true", true, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestRay()
+			if c.annotation != "" {
+				ray.Annotations = map[string]string{consts.AnnotationExternalReplicas: c.annotation}
+			}
+			if c.autoscaling {
+				ray.Spec.Autoscaling = &rayv1.AutoscalingSpec{MaxReplicas: 10}
+			}
+			deploys, err := newTestComposer().DesiredWorkers(ray)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			external := deploys[0].Annotations[consts.AnnotationExternalReplicas] == "true"
+			if external != c.expected {
+				t.Errorf("expected external replicas %v, got %v", c.expected, external)
+			}
+			if *deploys[0].Spec.Replicas != 1 {
+				t.Errorf("expected the replicas of the Ray, got %d", *deploys[0].Spec.Replicas)
+			}
+		})
+	}
+}
+
+func TestDesiredHeadStatefulSetGoverningService(t *testing.T) {
+	ray := newTestRay()
+	ray.Spec.Head.WorkloadKind = rayv1.HeadWorkloadStatefulSet
+	c := newTestComposer()
+
+	sts, err := c.DesiredHeadStatefulSet(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service, err := c.DesiredHeadGoverningService(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sts.Spec.ServiceName != service.Name || service.Name == GetHeadName(ray.Name) {
+		t.Errorf("expected the StatefulSet to be governed by the headless service, got %s and %s",
+			sts.Spec.ServiceName, service.Name)
+	}
+	if service.Spec.ClusterIP != corev1.ClusterIPNone {
+		t.Errorf("expected a headless service, got the cluster IP %q", service.Spec.ClusterIP)
+	}
+	for k, v := range service.Spec.Selector {
+		if sts.Spec.Template.Labels[k] != v {
+			t.Errorf("expected the service to select the head pod, got the selector %v and the labels %v",
+				service.Spec.Selector, sts.Spec.Template.Labels)
+		}
+	}
+}
+
+func TestDiscoveryEnv(t *testing.T) {
+	cases := []struct {
+		name           string
+		version        string
+		params         map[string]string
+		expectedGCSEnv string
+	}{
+		{"redis profile", "1.10.0", nil, "test-head.default.svc:6379"},
+		{"redis profile with redis port", "1.10.0", map[string]string{"redis-port": "7000"}, "test-head.default.svc:7000"},
+		{"gcs profile", "2.9.0", nil, "test-head.default.svc:6379"},
+		{"gcs profile with port", "2.9.0", map[string]string{"port": "7001"}, "test-head.default.svc:7001"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestRay()
+			ray.Spec.RayVersion = c.version
+			ray.Spec.RayStartParams = c.params
+			deploys, err := newTestComposer().DesiredWorkers(ray)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			container := findContainer(&deploys[0].Spec.Template, consts.ContainerRayWorker)
+			expected := map[string]string{
+				consts.EnvRayHeadService:       "test-head",
+				consts.EnvRayHeadServiceFQDN:   "test-head.default.svc",
+				consts.EnvRayGCSAddress:        c.expectedGCSEnv,
+				consts.EnvRayWorkerServiceFQDN: "test-workers.default.svc",
+			}
+			for name, value := range expected {
+				env := findEnv(container, name)
+				if env == nil || env.Value != value {
+					t.Errorf("expected %s=%s, got %v", name, value, env)
+				}
+			}
+		})
+	}
+}
+
+func TestDesiredWorkersWaitForHead(t *testing.T) {
+	ray := newTestRay()
+	ray.Spec.RayVersion = "2.9.0"
+	ray.Spec.RayStartParams = map[string]string{"port": "7001"}
+	deploys, err := newTestComposer().DesiredWorkers(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	template := &deploys[0].Spec.Template
+	if len(template.Spec.InitContainers) != 1 {
+		t.Fatalf("expected one init container, got %v", template.Spec.InitContainers)
+	}
+	init := &template.Spec.InitContainers[0]
+	if init.Name != consts.ContainerWaitForHead {
+		t.Errorf("expected the init container %s, got %s", consts.ContainerWaitForHead, init.Name)
+	}
+	if worker := findContainer(template, consts.ContainerRayWorker); init.Image != worker.Image {
+		t.Errorf("expected the image of the worker %s, got %s", worker.Image, init.Image)
+	}
+	if !reflect.DeepEqual(init.Command, defaultCmd) {
+		t.Errorf("expected the command %v, got %v", defaultCmd, init.Command)
+	}
+	expectedArgs := []string{fmt.Sprintf(
+		"until (exec 3<>/dev/tcp/${%s%%:*}/${%s##*:}) 2>/dev/null; do echo waiting for the head at $%s; sleep 2; done",
+		consts.EnvRayGCSAddress, consts.EnvRayGCSAddress, consts.EnvRayGCSAddress)}
+	if len(init.Args) != 1 || init.Args[0] != expectedArgs[0] {
+		t.Errorf("expected the args %v, got %v", expectedArgs, init.Args)
+	}
+	// The host and the port are split from the address by the shell.
+	if env := findEnv(init, consts.EnvRayGCSAddress); env == nil || env.Value != "test-head.default.svc:7001" {
+		t.Errorf("expected %s=test-head.default.svc:7001, got %v", consts.EnvRayGCSAddress, env)
+	}
+}
+
+func TestDesiredWorkersWaitForHeadNotDuplicated(t *testing.T) {
+	ray := newTestRay()
+	custom := corev1.Container{Name: consts.ContainerWaitForHead, Image: "busybox"}
+	ray.Spec.Worker.Template.Spec.InitContainers = []corev1.Container{custom}
+	deploys, err := newTestComposer().DesiredWorkers(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	initContainers := deploys[0].Spec.Template.Spec.InitContainers
+	if len(initContainers) != 1 || initContainers[0].Image != "busybox" {
+		t.Errorf("expected the init container of the user to be kept, got %v", initContainers)
+	}
+}
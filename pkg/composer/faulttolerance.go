@@ -0,0 +1,82 @@
+package composer
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// SupportsGCSFaultTolerance returns true if the GCS of the Ray version persists the
+// metadata to an external Redis, that is Ray 2.0 and later.
+func SupportsGCSFaultTolerance(version string) bool {
+	var major, minor int
+	if _, err := fmt.Sscanf(version, "%d.%d", &major, &minor); err != nil {
+		return false
+	}
+	return major >= 2
+}
+
+// setHeadFaultTolerance sets the environment variables of the Ray container of the head
+// which point the GCS to the external Redis. The password is passed to `ray start` by
+// the composed command line.
+func setHeadFaultTolerance(template *corev1.PodTemplateSpec, ray *rayv1.Ray) {
+	spec := ray.Spec.HeadFaultTolerance
+	if spec == nil {
+		return
+	}
+	namespace := spec.ExternalStorageNamespace
+	if namespace == "" {
+		namespace = string(ray.UID)
+	}
+	env := []corev1.EnvVar{
+		{
+			Name:  consts.EnvRayRedisAddress,
+			Value: spec.RedisAddress,
+		},
+		{
+			Name:  consts.EnvRayExternalStorageNamespace,
+			Value: namespace,
+		},
+	}
+	if spec.RedisPasswordSecret != nil {
+		env = append(env, corev1.EnvVar{
+			Name: consts.EnvRedisPassword,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: spec.RedisPasswordSecret.DeepCopy(),
+			},
+		})
+	}
+	appendRayContainerEnv(template, consts.ContainerRayHead, env)
+}
+
+// setWorkerFaultTolerance sets the duration the workers wait for a restarted head, so
+// that they are kept until the head recovers the cluster from the external Redis.
+func setWorkerFaultTolerance(template *corev1.PodTemplateSpec, ray *rayv1.Ray) {
+	spec := ray.Spec.HeadFaultTolerance
+	if spec == nil {
+		return
+	}
+	timeout := int32(rayv1.DefaultWorkerReconnectTimeoutSeconds)
+	if spec.WorkerReconnectTimeoutSeconds != nil {
+		timeout = *spec.WorkerReconnectTimeoutSeconds
+	}
+	appendRayContainerEnv(template, consts.ContainerRayWorker, []corev1.EnvVar{
+		{
+			Name:  consts.EnvRayGCSReconnectTimeout,
+			Value: strconv.Itoa(int(timeout)),
+		},
+	})
+}
+
+// appendRayContainerEnv appends the environment variables to the container named containerName.
+func appendRayContainerEnv(template *corev1.PodTemplateSpec, containerName string, env []corev1.EnvVar) {
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == containerName {
+			template.Spec.Containers[i].Env = append(template.Spec.Containers[i].Env, env...)
+		}
+	}
+}
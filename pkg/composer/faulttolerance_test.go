@@ -0,0 +1,134 @@
+package composer
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+func newTestFaultTolerantRay(password bool) *rayv1.Ray {
+	ray := newTestRay()
+	ray.UID = "test-uid"
+	ray.Spec.RayVersion = "2.9.0"
+	ray.Spec.HeadFaultTolerance = &rayv1.HeadFaultToleranceSpec{
+		RedisAddress: "redis.default.svc:6379",
+	}
+	if password {
+		ray.Spec.HeadFaultTolerance.RedisPasswordSecret = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "redis"},
+			Key:                  "password",
+		}
+	}
+	return ray
+}
+
+func TestSupportsGCSFaultTolerance(t *testing.T) {
+	cases := map[string]bool{
+		"":       false,
+		"1.13.0": false,
+		"2.0":    true,
+		"2.9.0":  true,
+	}
+	for version, expected := range cases {
+		if SupportsGCSFaultTolerance(version) != expected {
+			t.Errorf("version %q: expected %v", version, expected)
+		}
+	}
+}
+
+func TestDesiredHeadFaultTolerance(t *testing.T) {
+	cases := []struct {
+		name      string
+		password  bool
+		namespace string
+	}{
+		{name: "without password"},
+		{name: "with password", password: true},
+		{name: "with namespace", namespace: "shared"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestFaultTolerantRay(c.password)
+			ray.Spec.HeadFaultTolerance.ExternalStorageNamespace = c.namespace
+			// The password set by the user is replaced by the one of the secret.
+			ray.Spec.RayStartParams = map[string]string{FaultToleranceParam: "plain"}
+			head, err := newTestComposer().DesiredHead(ray)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			container := findContainer(&head.Spec.Template, consts.ContainerRayHead)
+
+			if env := findEnv(container, consts.EnvRayRedisAddress); env == nil || env.Value != "redis.default.svc:6379" {
+				t.Errorf("expected the redis address, got %v", env)
+			}
+			namespace := c.namespace
+			if namespace == "" {
+				namespace = string(ray.UID)
+			}
+			if env := findEnv(container, consts.EnvRayExternalStorageNamespace); env == nil || env.Value != namespace {
+				t.Errorf("expected the external storage namespace %s, got %v", namespace, env)
+			}
+
+			env := findEnv(container, consts.EnvRedisPassword)
+			passwordArg := "--redis-password=\"$" + consts.EnvRedisPassword + "\""
+			args := strings.Join(container.Args, " ")
+			if c.password {
+				if env == nil || env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil ||
+					env.ValueFrom.SecretKeyRef.Name != "redis" || env.ValueFrom.SecretKeyRef.Key != "password" {
+					t.Errorf("expected the password from the secret, got %v", env)
+				}
+				if !strings.Contains(args, passwordArg) {
+					t.Errorf("expected %s in %s", passwordArg, args)
+				}
+			} else {
+				if env != nil {
+					t.Errorf("expected no password, got %v", env)
+				}
+				if strings.Contains(args, "--redis-password") {
+					t.Errorf("expected no password in %s", args)
+				}
+			}
+			if strings.Contains(args, "plain") {
+				t.Errorf("expected the password of the params to be dropped, got %s", args)
+			}
+		})
+	}
+}
+
+func TestDesiredWorkersFaultTolerance(t *testing.T) {
+	cases := []struct {
+		name     string
+		timeout  *int32
+		expected string
+	}{
+		{name: "default timeout", expected: "600"},
+		{name: "timeout", timeout: int32Ptr(120), expected: "120"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestFaultTolerantRay(true)
+			ray.Spec.HeadFaultTolerance.WorkerReconnectTimeoutSeconds = c.timeout
+			workers, err := newTestComposer().DesiredWorkers(ray)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, w := range workers {
+				container := findContainer(&w.Spec.Template, consts.ContainerRayWorker)
+				if env := findEnv(container, consts.EnvRayGCSReconnectTimeout); env == nil || env.Value != c.expected {
+					t.Errorf("expected the reconnect timeout %s, got %v", c.expected, env)
+				}
+				// Only the head connects to the external Redis.
+				if findEnv(container, consts.EnvRedisPassword) != nil || findEnv(container, consts.EnvRayRedisAddress) != nil {
+					t.Errorf("expected no redis env on the workers, got %v", container.Env)
+				}
+				if strings.Contains(strings.Join(container.Args, " "), "--redis-password") {
+					t.Errorf("expected no password in the args of the workers, got %v", container.Args)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package composer
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// setSpecHash sets the hash of the desired spec to the annotation of the object. The
+// controller compares it with the annotation of the actual object to find out if the
+// object should be updated, since the actual spec is defaulted by the apiserver.
+func setSpecHash(meta *metav1.ObjectMeta, spec interface{}) error {
+	hash, err := GetHash(spec)
+	if err != nil {
+		return err
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[consts.AnnotationSpecHash] = hash
+	return nil
+}
+
+// GetHash returns the hex encoded fnv64a hash of the JSON encoding of the object.
+func GetHash(obj interface{}) (string, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	hasher := fnv.New64a()
+	if _, err := hasher.Write(data); err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(hasher.Sum64(), 16), nil
+}
@@ -0,0 +1,95 @@
+package composer
+
+import (
+	"fmt"
+
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// DesiredHeadIngress gets the desired specification of the Ingress which routes the
+// dashboard host and the client host to the head service.
+func (c Composer) DesiredHeadIngress(ray *rayv1.Ray) (*networkingv1beta1.Ingress, error) {
+	spec := ray.Spec.Head.Ingress
+	if spec == nil {
+		return nil, fmt.Errorf("the ingress of the Ray %s is not specified", ray.Name)
+	}
+
+	annotations := map[string]string{}
+	for k, v := range spec.Annotations {
+		annotations[k] = v
+	}
+	ingress := &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        GetHeadName(ray.Name),
+			Namespace:   ray.Namespace,
+			Labels:      ray.Labels,
+			Annotations: annotations,
+		},
+	}
+	hosts := []string{}
+	for _, route := range []struct {
+		host string
+		port int32
+	}{
+		{spec.DashboardHost, GetDashboardPort(&ray.Spec)},
+		{spec.ClientHost, GetClientPort(&ray.Spec)},
+	} {
+		if route.host == "" {
+			continue
+		}
+		hosts = append(hosts, route.host)
+		ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1beta1.IngressRule{
+			Host: route.host,
+			IngressRuleValue: networkingv1beta1.IngressRuleValue{
+				HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+					Paths: []networkingv1beta1.HTTPIngressPath{
+						{
+							Backend: networkingv1beta1.IngressBackend{
+								ServiceName: GetHeadName(ray.Name),
+								ServicePort: intstr.FromInt(int(route.port)),
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+	if spec.TLSSecretName != "" {
+		ingress.Spec.TLS = []networkingv1beta1.IngressTLS{
+			{
+				Hosts:      hosts,
+				SecretName: spec.TLSSecretName,
+			},
+		}
+	}
+	if err := setSpecHash(&ingress.ObjectMeta, []interface{}{spec.Annotations, ingress.Spec}); err != nil {
+		return nil, err
+	}
+	if err := controllerutil.SetControllerReference(ray, ingress, c.scheme); err != nil {
+		return nil, err
+	}
+	return ingress, nil
+}
+
+// GetDashboardPort returns the dashboard port of the head. The head serves the
+// dashboard only if SupportsDashboard returns true for the Ray version.
+func GetDashboardPort(spec *rayv1.RaySpec) int32 {
+	if port := rayStartPort(spec, consts.PortNameDashboard); port != 0 {
+		return port
+	}
+	return consts.DefaultDashboardPort
+}
+
+// GetClientPort returns the Ray client server port of the head.
+func GetClientPort(spec *rayv1.RaySpec) int32 {
+	if port := rayStartPort(spec, consts.PortNameClient); port != 0 {
+		return port
+	}
+	return consts.DefaultClientPort
+}
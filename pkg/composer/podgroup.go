@@ -0,0 +1,147 @@
+package composer
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// defaultMinWorkers is the number of the workers gang scheduled with the head when
+// the autoscaling is enabled without the minimum replicas, the same as the autoscaler.
+const defaultMinWorkers = 1
+
+// DesiredPodGroup gets the desired specification of the PodGroup which gang schedules
+// the head and the minimum workers. The PodGroup is a custom resource of the gang
+// scheduler which is not known to the operator, thus it is unstructured.
+func (c Composer) DesiredPodGroup(ray *rayv1.Ray) (*unstructured.Unstructured, error) {
+	spec := ray.Spec.Scheduling
+	if spec == nil {
+		return nil, fmt.Errorf("the scheduling of the Ray %s is not specified", ray.Name)
+	}
+	gvk, err := GetPodGroupGVK(spec.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	podGroupSpec := map[string]interface{}{
+		"minMember": int64(GetPodGroupMinMember(ray)),
+	}
+	switch spec.Provider {
+	case rayv1.SchedulingVolcano:
+		if spec.Queue != "" {
+			podGroupSpec["queue"] = spec.Queue
+		}
+	case rayv1.SchedulingCoscheduling:
+		if spec.ScheduleTimeoutSeconds != nil {
+			podGroupSpec["scheduleTimeoutSeconds"] = int64(*spec.ScheduleTimeoutSeconds)
+		}
+	}
+	podGroup := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": podGroupSpec,
+		},
+	}
+	podGroup.SetGroupVersionKind(gvk)
+	podGroup.SetName(GetPodGroupName(ray.Name))
+	podGroup.SetNamespace(ray.Namespace)
+	podGroup.SetLabels(ray.Labels)
+	hash, err := GetHash(podGroupSpec)
+	if err != nil {
+		return nil, err
+	}
+	podGroup.SetAnnotations(map[string]string{
+		consts.AnnotationSpecHash: hash,
+	})
+	if err := controllerutil.SetControllerReference(ray, podGroup, c.scheme); err != nil {
+		return nil, err
+	}
+	return podGroup, nil
+}
+
+// setGangScheduling sets the scheduler of the pods and adds them to the PodGroup if the
+// gang scheduling is enabled. The labels and the annotations are copied before they are
+// changed, since they may be shared with the selector.
+func setGangScheduling(template *corev1.PodTemplateSpec, ray *rayv1.Ray) {
+	spec := ray.Spec.Scheduling
+	if spec == nil {
+		return
+	}
+	template.Spec.SchedulerName = GetSchedulerName(spec)
+	name := GetPodGroupName(ray.Name)
+	switch spec.Provider {
+	case rayv1.SchedulingVolcano:
+		template.Annotations = withEntry(template.Annotations, consts.AnnotationVolcanoGroupName, name)
+	case rayv1.SchedulingCoscheduling:
+		template.Labels = withEntry(template.Labels, consts.LabelCoschedulingPodGroup, name)
+	}
+}
+
+// withEntry returns a copy of the map with the key set to the value.
+func withEntry(m map[string]string, key, value string) map[string]string {
+	out := make(map[string]string, len(m)+1)
+	for k, v := range m {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+// GetPodGroupMinMember returns the number of the pods which must be scheduled together,
+// that is the head and the minimum workers.
+func GetPodGroupMinMember(ray *rayv1.Ray) int32 {
+	minMember := int32(1)
+	if ray.Spec.Head != nil && ray.Spec.Head.Replicas != nil {
+		minMember = *ray.Spec.Head.Replicas
+	}
+	if spec := ray.Spec.Scheduling; spec != nil && spec.MinWorkers != nil {
+		return minMember + *spec.MinWorkers
+	}
+	if autoscaling := ray.Spec.Autoscaling; autoscaling != nil {
+		if autoscaling.MinReplicas != nil {
+			return minMember + *autoscaling.MinReplicas
+		}
+		return minMember + defaultMinWorkers
+	}
+	for _, group := range ray.Spec.GetWorkerGroups() {
+		if group.Replicas != nil {
+			minMember += *group.Replicas
+		} else {
+			// The replicas of a Deployment default to 1.
+			minMember++
+		}
+	}
+	return minMember
+}
+
+// GetPodGroupGVK returns the group, version and kind of the PodGroup of the provider.
+func GetPodGroupGVK(provider rayv1.SchedulingProvider) (schema.GroupVersionKind, error) {
+	switch provider {
+	case rayv1.SchedulingVolcano:
+		return schema.GroupVersionKind{Group: "scheduling.volcano.sh", Version: "v1beta1", Kind: "PodGroup"}, nil
+	case rayv1.SchedulingCoscheduling:
+		return schema.GroupVersionKind{Group: "scheduling.x-k8s.io", Version: "v1alpha1", Kind: "PodGroup"}, nil
+	}
+	return schema.GroupVersionKind{}, fmt.Errorf("unknown scheduling provider %q", provider)
+}
+
+// GetSchedulerName returns the scheduler of the pods when they are gang scheduled.
+func GetSchedulerName(spec *rayv1.SchedulingSpec) string {
+	if spec.SchedulerName != "" {
+		return spec.SchedulerName
+	}
+	if spec.Provider == rayv1.SchedulingCoscheduling {
+		return consts.DefaultCoschedulingSchedulerName
+	}
+	return consts.DefaultVolcanoSchedulerName
+}
+
+// GetPodGroupName returns the name of the PodGroup, which is the name of the Ray.
+func GetPodGroupName(rayName string) string {
+	return rayName
+}
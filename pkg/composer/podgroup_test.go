@@ -0,0 +1,209 @@
+package composer
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+func TestDesiredPodGroup(t *testing.T) {
+	cases := []struct {
+		name       string
+		scheduling *rayv1.SchedulingSpec
+		apiVersion string
+		field      string
+		value      int64
+	}{
+		{
+			name: "volcano",
+			scheduling: &rayv1.SchedulingSpec{
+				Provider: rayv1.SchedulingVolcano,
+				Queue:    "ml",
+			},
+			apiVersion: "scheduling.volcano.sh/v1beta1",
+		},
+		{
+			name: "coscheduling",
+			scheduling: &rayv1.SchedulingSpec{
+				Provider:               rayv1.SchedulingCoscheduling,
+				ScheduleTimeoutSeconds: int32Ptr(60),
+			},
+			apiVersion: "scheduling.x-k8s.io/v1alpha1",
+			field:      "scheduleTimeoutSeconds",
+			value:      60,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestRay()
+			ray.UID = "test-uid"
+			ray.Spec.Worker.Replicas = int32Ptr(3)
+			ray.Spec.Scheduling = c.scheduling
+			podGroup, err := newTestComposer().DesiredPodGroup(ray)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if podGroup.GetAPIVersion() != c.apiVersion || podGroup.GetKind() != "PodGroup" {
+				t.Errorf("expected a PodGroup of %s, got %s %s", c.apiVersion,
+					podGroup.GetAPIVersion(), podGroup.GetKind())
+			}
+			if podGroup.GetName() != GetPodGroupName(ray.Name) || podGroup.GetNamespace() != ray.Namespace {
+				t.Errorf("expected the PodGroup %s/%s, got %s/%s", ray.Namespace, GetPodGroupName(ray.Name),
+					podGroup.GetNamespace(), podGroup.GetName())
+			}
+			if podGroup.GetAnnotations()[consts.AnnotationSpecHash] == "" {
+				t.Errorf("expected the spec hash annotation")
+			}
+			owners := podGroup.GetOwnerReferences()
+			if len(owners) != 1 || owners[0].UID != ray.UID {
+				t.Errorf("expected the PodGroup to be controlled by the Ray, got %v", owners)
+			}
+			// The head and the three workers.
+			if n, _, _ := unstructured.NestedInt64(podGroup.Object, "spec", "minMember"); n != 4 {
+				t.Errorf("expected minMember 4, got %d", n)
+			}
+			queue, found, _ := unstructured.NestedString(podGroup.Object, "spec", "queue")
+			if (c.scheduling.Queue != "") != found || queue != c.scheduling.Queue {
+				t.Errorf("expected the queue %q, got %q", c.scheduling.Queue, queue)
+			}
+			if c.field != "" {
+				if n, _, _ := unstructured.NestedInt64(podGroup.Object, "spec", c.field); n != c.value {
+					t.Errorf("expected %s %d, got %d", c.field, c.value, n)
+				}
+			}
+		})
+	}
+}
+
+func TestDesiredPodGroupUnknownProvider(t *testing.T) {
+	ray := newTestRay()
+	ray.Spec.Scheduling = &rayv1.SchedulingSpec{Provider: "Kueue"}
+	if _, err := newTestComposer().DesiredPodGroup(ray); err == nil {
+		t.Error("expected an error for the unknown provider")
+	}
+}
+
+func TestGetPodGroupMinMember(t *testing.T) {
+	cases := []struct {
+		name     string
+		mutate   func(ray *rayv1.Ray)
+		expected int32
+	}{
+		{
+			name: "worker replicas",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Worker.Replicas = int32Ptr(2)
+			},
+			expected: 3,
+		},
+		{
+			name: "worker groups",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.WorkerGroups = []rayv1.WorkerGroupSpec{
+					{Name: "cpu", ReplicaSpec: rayv1.ReplicaSpec{Replicas: int32Ptr(2)}},
+					// The replicas of a Deployment default to 1.
+					{Name: "gpu"},
+				}
+			},
+			expected: 4,
+		},
+		{
+			name: "autoscaling",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Autoscaling = &rayv1.AutoscalingSpec{MinReplicas: int32Ptr(2), MaxReplicas: 10}
+			},
+			expected: 3,
+		},
+		{
+			name: "autoscaling without min replicas",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Autoscaling = &rayv1.AutoscalingSpec{MaxReplicas: 10}
+			},
+			expected: 1 + defaultMinWorkers,
+		},
+		{
+			name: "min workers",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Worker.Replicas = int32Ptr(5)
+				ray.Spec.Scheduling.MinWorkers = int32Ptr(2)
+			},
+			expected: 3,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestRay()
+			ray.Spec.Scheduling = &rayv1.SchedulingSpec{Provider: rayv1.SchedulingVolcano}
+			c.mutate(ray)
+			if n := GetPodGroupMinMember(ray); n != c.expected {
+				t.Errorf("expected %d, got %d", c.expected, n)
+			}
+		})
+	}
+}
+
+func TestSetGangScheduling(t *testing.T) {
+	cases := []struct {
+		name          string
+		scheduling    *rayv1.SchedulingSpec
+		schedulerName string
+		annotation    string
+		label         string
+	}{
+		{
+			name:          "volcano",
+			scheduling:    &rayv1.SchedulingSpec{Provider: rayv1.SchedulingVolcano},
+			schedulerName: consts.DefaultVolcanoSchedulerName,
+			annotation:    consts.AnnotationVolcanoGroupName,
+		},
+		{
+			name: "coscheduling",
+			scheduling: &rayv1.SchedulingSpec{
+				Provider:      rayv1.SchedulingCoscheduling,
+				SchedulerName: "my-scheduler",
+			},
+			schedulerName: "my-scheduler",
+			label:         consts.LabelCoschedulingPodGroup,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestRay()
+			ray.Spec.Scheduling = c.scheduling
+			head, err := newTestComposer().DesiredHead(ray)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			workers, err := newTestComposer().DesiredWorkers(ray)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			templates := []*corev1.PodTemplateSpec{&head.Spec.Template}
+			for _, w := range workers {
+				templates = append(templates, &w.Spec.Template)
+			}
+			name := GetPodGroupName(ray.Name)
+			for _, template := range templates {
+				if template.Spec.SchedulerName != c.schedulerName {
+					t.Errorf("expected the scheduler %s, got %s", c.schedulerName, template.Spec.SchedulerName)
+				}
+				if c.annotation != "" && template.Annotations[c.annotation] != name {
+					t.Errorf("expected the annotation %s=%s, got %v", c.annotation, name, template.Annotations)
+				}
+				if c.label != "" && template.Labels[c.label] != name {
+					t.Errorf("expected the label %s=%s, got %v", c.label, name, template.Labels)
+				}
+			}
+			// The membership is not added to the selector.
+			if c.label != "" {
+				if _, ok := head.Spec.Selector.MatchLabels[c.label]; ok {
+					t.Errorf("expected the selector without %s, got %v", c.label, head.Spec.Selector)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package composer
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+)
+
+const (
+	defaultProbeInitialDelaySeconds = 10
+	defaultProbeTimeoutSeconds      = 5
+	defaultProbePeriodSeconds       = 10
+	defaultProbeFailureThreshold    = 3
+
+	// The liveness probe waits longer than the readiness probe, since restarting the
+	// container of a slowly starting Ray only delays it further.
+	defaultLivenessInitialDelaySeconds = 30
+	defaultLivenessFailureThreshold    = 6
+)
+
+// setDefaultProbes adds the TCP readiness and liveness probes on the port to the
+// container which does not define them. Without the probes, the pod is ready as soon
+// as the shell running `ray start` starts. The probes are not added if the container
+// does not expose the port, e.g. when the user starts Ray with other ports in the args.
+func setDefaultProbes(template *corev1.PodTemplateSpec, containerName string,
+	port int32, spec *rayv1.ProbeSpec) {
+	for i := range template.Spec.Containers {
+		c := &template.Spec.Containers[i]
+		if c.Name != containerName || !hasContainerPort(c, port) {
+			continue
+		}
+		if spec == nil {
+			spec = &rayv1.ProbeSpec{}
+		}
+		if c.ReadinessProbe == nil {
+			c.ReadinessProbe = desiredTCPProbe(port, spec,
+				int32OrDefault(spec.InitialDelaySeconds, defaultProbeInitialDelaySeconds),
+				int32OrDefault(spec.FailureThreshold, defaultProbeFailureThreshold))
+		}
+		if c.LivenessProbe == nil {
+			c.LivenessProbe = desiredTCPProbe(port, spec,
+				int32OrDefault(spec.LivenessInitialDelaySeconds, defaultLivenessInitialDelaySeconds),
+				int32OrDefault(spec.LivenessFailureThreshold, defaultLivenessFailureThreshold))
+		}
+	}
+}
+
+func desiredTCPProbe(port int32, spec *rayv1.ProbeSpec,
+	initialDelaySeconds, failureThreshold int32) *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Port: intstr.FromInt(int(port)),
+			},
+		},
+		InitialDelaySeconds: initialDelaySeconds,
+		TimeoutSeconds:      int32OrDefault(spec.TimeoutSeconds, defaultProbeTimeoutSeconds),
+		PeriodSeconds:       int32OrDefault(spec.PeriodSeconds, defaultProbePeriodSeconds),
+		FailureThreshold:    failureThreshold,
+	}
+}
+
+func hasContainerPort(c *corev1.Container, port int32) bool {
+	for _, p := range c.Ports {
+		if p.ContainerPort == port {
+			return true
+		}
+	}
+	return false
+}
+
+func int32OrDefault(n *int32, defaultValue int32) int32 {
+	if n == nil {
+		return defaultValue
+	}
+	return *n
+}
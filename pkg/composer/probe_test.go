@@ -0,0 +1,100 @@
+package composer
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+)
+
+func int32Ptr(n int32) *int32 {
+	return &n
+}
+
+func newTestProbeTemplate(port int32) *corev1.PodTemplateSpec {
+	return &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  "ray",
+					Ports: []corev1.ContainerPort{{ContainerPort: port}},
+				},
+			},
+		},
+	}
+}
+
+func TestSetDefaultProbes(t *testing.T) {
+	template := newTestProbeTemplate(6379)
+	setDefaultProbes(template, "ray", 6379, nil)
+	c := findContainer(template, "ray")
+	if c.ReadinessProbe == nil || c.LivenessProbe == nil {
+		t.Fatalf("expected the probes, got %+v", c)
+	}
+	for _, probe := range []*corev1.Probe{c.ReadinessProbe, c.LivenessProbe} {
+		if probe.TCPSocket == nil || probe.TCPSocket.Port.IntValue() != 6379 {
+			t.Errorf("expected a TCP probe on 6379, got %+v", probe.Handler)
+		}
+		if probe.TimeoutSeconds != defaultProbeTimeoutSeconds || probe.PeriodSeconds != defaultProbePeriodSeconds {
+			t.Errorf("expected the default timeout and period, got %+v", probe)
+		}
+	}
+	if c.ReadinessProbe.InitialDelaySeconds != defaultProbeInitialDelaySeconds ||
+		c.ReadinessProbe.FailureThreshold != defaultProbeFailureThreshold {
+		t.Errorf("expected the default readiness probe, got %+v", c.ReadinessProbe)
+	}
+	// The liveness probe must not restart a Ray which is still starting.
+	if c.LivenessProbe.InitialDelaySeconds <= c.ReadinessProbe.InitialDelaySeconds ||
+		c.LivenessProbe.FailureThreshold <= c.ReadinessProbe.FailureThreshold {
+		t.Errorf("expected the liveness probe to be more tolerant than the readiness probe, got %+v", c.LivenessProbe)
+	}
+}
+
+func TestSetDefaultProbesSpec(t *testing.T) {
+	template := newTestProbeTemplate(6379)
+	spec := &rayv1.ProbeSpec{
+		InitialDelaySeconds:         int32Ptr(20),
+		TimeoutSeconds:              int32Ptr(2),
+		PeriodSeconds:               int32Ptr(15),
+		FailureThreshold:            int32Ptr(4),
+		LivenessInitialDelaySeconds: int32Ptr(90),
+		LivenessFailureThreshold:    int32Ptr(10),
+	}
+	setDefaultProbes(template, "ray", 6379, spec)
+	c := findContainer(template, "ray")
+	readiness, liveness := c.ReadinessProbe, c.LivenessProbe
+	if readiness.InitialDelaySeconds != 20 || readiness.FailureThreshold != 4 ||
+		readiness.TimeoutSeconds != 2 || readiness.PeriodSeconds != 15 {
+		t.Errorf("expected the readiness probe of the spec, got %+v", readiness)
+	}
+	if liveness.InitialDelaySeconds != 90 || liveness.FailureThreshold != 10 ||
+		liveness.TimeoutSeconds != 2 || liveness.PeriodSeconds != 15 {
+		t.Errorf("expected the liveness probe of the spec, got %+v", liveness)
+	}
+}
+
+func TestSetDefaultProbesSkipped(t *testing.T) {
+	// The probes defined by the user are kept.
+	template := newTestProbeTemplate(6379)
+	userProbe := &corev1.Probe{
+		Handler: corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"true"}}},
+	}
+	template.Spec.Containers[0].LivenessProbe = userProbe
+	setDefaultProbes(template, "ray", 6379, nil)
+	c := findContainer(template, "ray")
+	if c.LivenessProbe != userProbe {
+		t.Errorf("expected the liveness probe of the user, got %+v", c.LivenessProbe)
+	}
+	if c.ReadinessProbe == nil {
+		t.Errorf("expected the default readiness probe")
+	}
+
+	// The probes are not added if the container does not expose the port.
+	template = newTestProbeTemplate(6380)
+	setDefaultProbes(template, "ray", 6379, nil)
+	c = findContainer(template, "ray")
+	if c.ReadinessProbe != nil || c.LivenessProbe != nil {
+		t.Errorf("expected no probes, got %+v", c)
+	}
+}
@@ -0,0 +1,112 @@
+package composer
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// DesiredRayForJob gets the desired specification of the Ray cluster which runs the job.
+func (c Composer) DesiredRayForJob(job *rayv1.RayJob) (*rayv1.Ray, error) {
+	labels := map[string]string{
+		consts.LabelRayJob: job.Name,
+	}
+	for k, v := range job.Labels {
+		labels[k] = v
+	}
+
+	ray := &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetRayNameForJob(job.Name),
+			Namespace: job.Namespace,
+			Labels:    labels,
+		},
+		Spec: *job.Spec.RaySpec.DeepCopy(),
+	}
+	// The Ray is created by the controller, thus it may not be defaulted by the
+	// mutating webhook, and the submitter needs the image of the head.
+	ray.Default()
+	if err := controllerutil.SetControllerReference(job, ray, c.scheme); err != nil {
+		return nil, err
+	}
+	return ray, nil
+}
+
+// DesiredSubmitter gets the desired specification of the Kubernetes Job which submits
+// the entrypoint to the Ray cluster. The submitter uses the image of the head, and it
+// waits until the Ray job finishes, thus the status of the Ray job is the status of it.
+func (c Composer) DesiredSubmitter(job *rayv1.RayJob, ray *rayv1.Ray) (*batchv1.Job, error) {
+	image := ""
+	if ray.Spec.Head != nil && ray.Spec.Head.Template != nil {
+		for _, container := range ray.Spec.Head.Template.Spec.Containers {
+			if container.Name == consts.ContainerRayHead {
+				image = container.Image
+			}
+		}
+	}
+	if image == "" {
+		return nil, fmt.Errorf("failed to find the image of the container %s in the Ray %s",
+			consts.ContainerRayHead, ray.Name)
+	}
+
+	command := []string{
+		"ray", "job", "submit",
+		"--address", fmt.Sprintf("http://%s.%s.svc:%d",
+			GetHeadName(ray.Name), ray.Namespace, GetDashboardPort(&ray.Spec)),
+	}
+	if job.Spec.RuntimeEnv != "" {
+		command = append(command, "--runtime-env-json", job.Spec.RuntimeEnv)
+	}
+	command = append(command, "--", job.Spec.Entrypoint)
+
+	labels := map[string]string{
+		consts.LabelRayJob: job.Name,
+	}
+	backoffLimit := int32(0)
+	submitter := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetSubmitterName(job.Name),
+			Namespace: job.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			// The entrypoint must not be run twice.
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    consts.ContainerRaySubmitter,
+							Image:   image,
+							Command: command,
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(job, submitter, c.scheme); err != nil {
+		return nil, err
+	}
+	return submitter, nil
+}
+
+// GetRayNameForJob returns the name of the Ray cluster which runs the job.
+func GetRayNameForJob(jobName string) string {
+	return fmt.Sprintf("%s-cluster", jobName)
+}
+
+// GetSubmitterName returns the name of the Kubernetes Job which submits the job.
+func GetSubmitterName(jobName string) string {
+	return fmt.Sprintf("%s-submitter", jobName)
+}
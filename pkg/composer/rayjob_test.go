@@ -0,0 +1,36 @@
+package composer
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+func TestDesiredRayForJobDefaults(t *testing.T) {
+	job := &rayv1.RayJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint: "python main.py",
+		},
+	}
+	c := newTestComposer()
+	ray, err := c.DesiredRayForJob(job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ray.Spec.Head == nil || findContainer(ray.Spec.Head.Template, consts.ContainerRayHead) == nil {
+		t.Fatalf("expected the head of the Ray to be defaulted, got %v", ray.Spec.Head)
+	}
+	if job.Spec.RaySpec.Head != nil {
+		t.Errorf("expected the spec of the RayJob not to be modified, got %v", job.Spec.RaySpec.Head)
+	}
+	if _, err := c.DesiredSubmitter(job, ray); err != nil {
+		t.Errorf("expected the submitter to find the image of the head, got %v", err)
+	}
+}
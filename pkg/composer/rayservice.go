@@ -0,0 +1,88 @@
+package composer
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// DesiredRayForService gets the desired specification of the Ray cluster which serves
+// the deployments. The name of the cluster contains the hash of the cluster spec, thus
+// a new cluster is created when the spec is changed.
+func (c Composer) DesiredRayForService(svc *rayv1.RayService) (*rayv1.Ray, error) {
+	hash, err := GetHash(svc.Spec.RaySpec)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{
+		consts.LabelRayService: svc.Name,
+	}
+	for k, v := range svc.Labels {
+		labels[k] = v
+	}
+
+	ray := &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetRayNameForService(svc.Name, hash),
+			Namespace: svc.Namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				consts.AnnotationSpecHash: hash,
+			},
+		},
+		Spec: *svc.Spec.RaySpec.DeepCopy(),
+	}
+	if err := controllerutil.SetControllerReference(svc, ray, c.scheme); err != nil {
+		return nil, err
+	}
+	return ray, nil
+}
+
+// DesiredServeService gets the desired specification of the stable service which
+// selects the head of the given Ray cluster. The traffic is switched to another
+// cluster by updating the selector.
+func (c Composer) DesiredServeService(svc *rayv1.RayService, ray *rayv1.Ray) (*corev1.Service, error) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetServeServiceName(svc.Name),
+			Namespace: svc.Namespace,
+			Labels: map[string]string{
+				consts.LabelRayService: svc.Name,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: getHeadPodLabels(ray.Name),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "serve",
+					Port:       consts.DefaultServePort,
+					TargetPort: intstr.FromInt(consts.DefaultServePort),
+				},
+			},
+		},
+	}
+	if err := setSpecHash(&service.ObjectMeta, service.Spec); err != nil {
+		return nil, err
+	}
+	if err := controllerutil.SetControllerReference(svc, service, c.scheme); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// GetRayNameForService returns the name of the Ray cluster with the given spec hash.
+func GetRayNameForService(serviceName, hash string) string {
+	return fmt.Sprintf("%s-cluster-%s", serviceName, hash)
+}
+
+// GetServeServiceName returns the name of the stable service of the RayService.
+func GetServeServiceName(serviceName string) string {
+	return fmt.Sprintf("%s-serve", serviceName)
+}
@@ -0,0 +1,268 @@
+package composer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+var (
+	// defaultCmd runs the `ray start` command line in the args.
+	defaultCmd = []string{
+		"/bin/bash",
+		"-c",
+		"--",
+	}
+
+	// safeParamValue matches the values of the parameters which are not quoted.
+	safeParamValue = regexp.MustCompile(`^[A-Za-z0-9_.,:/@%+=-]+$`)
+
+	// headOnlyParams are the parameters which are not passed to the workers.
+	headOnlyParams = map[string]bool{
+		"port":                   true,
+		"redis-port":             true,
+		"redis-shard-ports":      true,
+		"dashboard-host":         true,
+		"dashboard-port":         true,
+		"include-dashboard":      true,
+		"ray-client-server-port": true,
+	}
+
+	// managedParams are set by the operator and cannot be overridden.
+	managedParams = []string{"head", "address", "redis-address", "node-ip-address", "resources", "block"}
+)
+
+// FaultToleranceParam is the parameter of `ray start` which is set by the operator if
+// the GCS fault tolerance is enabled.
+const FaultToleranceParam = "redis-password"
+
+// rayPort is a port of the Ray node which is set by a parameter of `ray start`.
+type rayPort struct {
+	name string
+	// param is the parameter which sets the port, the port is fixed if it is empty.
+	param string
+	port  int32
+	// headOnly is true if only the head listens on the port.
+	headOnly bool
+}
+
+// portProfile is the layout of the ports of a range of Ray versions.
+type portProfile struct {
+	// gcsPortName is the name of the port of the head which the workers connect to.
+	gcsPortName string
+	// headParams are the fixed parameters of the head.
+	headParams map[string]string
+	ports      []rayPort
+	// workerAddress returns the parameter of the workers which connects to the head.
+	workerAddress func(gcsPort int32) string
+}
+
+var (
+	// redisProfile is used by Ray before 1.11, where the GCS stores its tables in
+	// the Redis primary and shards on the head.
+	redisProfile = &portProfile{
+		gcsPortName: consts.PortNameRedisPrimary,
+		headParams: map[string]string{
+			"redis-shard-ports": "6380,6381",
+		},
+		ports: []rayPort{
+			{name: consts.PortNameRedisPrimary, param: "redis-port", port: consts.DefaultGCSPort, headOnly: true},
+			{name: "redis-shard-0", port: 6380, headOnly: true},
+			{name: "redis-shard-1", port: 6381, headOnly: true},
+			{name: "object-manager", param: "object-manager-port", port: 12345},
+			{name: consts.PortNameNodeManager, param: "node-manager-port", port: consts.DefaultNodeManagerPort},
+		},
+		workerAddress: func(gcsPort int32) string {
+			return fmt.Sprintf("--redis-address=$(python -c 'import socket;import sys;import os; sys.stdout.write(socket.gethostbyname(os.environ[\"%s\"]));sys.stdout.flush()'):%d",
+				consts.EnvRayHeadService, gcsPort)
+		},
+	}
+
+	// gcsProfile is used by Ray 1.11 and later, where the GCS runs without Redis and
+	// the head serves the dashboard, the Ray client and the metrics.
+	gcsProfile = &portProfile{
+		gcsPortName: consts.PortNameGCS,
+		headParams: map[string]string{
+			"dashboard-host": "0.0.0.0",
+		},
+		ports: []rayPort{
+			{name: consts.PortNameGCS, param: "port", port: consts.DefaultGCSPort, headOnly: true},
+			{name: consts.PortNameDashboard, param: "dashboard-port", port: consts.DefaultDashboardPort, headOnly: true},
+			{name: consts.PortNameClient, param: "ray-client-server-port", port: consts.DefaultClientPort, headOnly: true},
+			{name: "metrics", param: "metrics-export-port", port: 8080},
+			{name: "object-manager", param: "object-manager-port", port: 12345},
+			{name: consts.PortNameNodeManager, param: "node-manager-port", port: consts.DefaultNodeManagerPort},
+		},
+		workerAddress: func(int32) string {
+			return fmt.Sprintf("--address=$%s", consts.EnvRayGCSAddress)
+		},
+	}
+)
+
+// getPortProfile returns the port profile of the Ray version. The version is validated
+// in the form of major.minor[.patch].
+func getPortProfile(version string) *portProfile {
+	var major, minor int
+	if _, err := fmt.Sscanf(version, "%d.%d", &major, &minor); err != nil {
+		return redisProfile
+	}
+	if major > 1 || (major == 1 && minor >= 11) {
+		return gcsProfile
+	}
+	return redisProfile
+}
+
+// SupportsDashboard returns true if the head of the Ray version serves the dashboard,
+// which the job submission, the serve config, the autoscaler and the node prober talk
+// to, that is Ray 1.11 and later.
+func SupportsDashboard(version string) bool {
+	return getPortProfile(version) == gcsProfile
+}
+
+// IsManagedRayStartParam returns true if the parameter of `ray start` is set by the operator.
+func IsManagedRayStartParam(name string) bool {
+	for _, p := range managedParams {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// rayStartPorts returns the ports of the head or the workers, which are overridden
+// by the parameters of `ray start`.
+func rayStartPorts(spec *rayv1.RaySpec, head bool) []corev1.ContainerPort {
+	profile := getPortProfile(spec.RayVersion)
+	ports := []corev1.ContainerPort{}
+	for _, p := range profile.ports {
+		if p.headOnly && !head {
+			continue
+		}
+		ports = append(ports, corev1.ContainerPort{
+			Name:          p.name,
+			ContainerPort: paramPort(spec, p.param, p.port),
+		})
+	}
+	return ports
+}
+
+// rayStartPort returns the port named name of the Ray node, or 0 if the port profile
+// does not have the port.
+func rayStartPort(spec *rayv1.RaySpec, name string) int32 {
+	for _, p := range getPortProfile(spec.RayVersion).ports {
+		if p.name == name {
+			return paramPort(spec, p.param, p.port)
+		}
+	}
+	return 0
+}
+
+// paramPort returns the port set by the parameter, or the default port if it is not
+// set or is invalid.
+func paramPort(spec *rayv1.RaySpec, param string, defaultPort int32) int32 {
+	if param == "" {
+		return defaultPort
+	}
+	v, ok := spec.RayStartParams[param]
+	if !ok {
+		return defaultPort
+	}
+	port, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		return defaultPort
+	}
+	return int32(port)
+}
+
+// rayStartArgs returns the `ray start` command line of the head or the workers.
+func rayStartArgs(spec *rayv1.RaySpec, head bool) []string {
+	profile := getPortProfile(spec.RayVersion)
+	params := map[string]string{}
+	for _, p := range profile.ports {
+		if p.param != "" && (head || !p.headOnly) {
+			params[p.param] = strconv.Itoa(int(p.port))
+		}
+	}
+	if head {
+		for k, v := range profile.headParams {
+			params[k] = v
+		}
+	}
+	ft := spec.HeadFaultTolerance
+	for k, v := range spec.RayStartParams {
+		if IsManagedRayStartParam(k) || (!head && headOnlyParams[k]) ||
+			(ft != nil && k == FaultToleranceParam) {
+			continue
+		}
+		params[k] = v
+	}
+
+	args := []string{"ray start"}
+	if head {
+		args = append(args, "--head")
+	} else {
+		args = append(args, profile.workerAddress(GetGCSPort(spec)))
+	}
+	names := make([]string, 0, len(params))
+	for k := range params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		args = append(args, formatParam(k, params[k]))
+	}
+	args = append(args, fmt.Sprintf("--node-ip-address=$%s", consts.EnvNodeIP))
+	if head && ft != nil && ft.RedisPasswordSecret != nil {
+		args = append(args, fmt.Sprintf("--%s=\"$%s\"", FaultToleranceParam, consts.EnvRedisPassword))
+	}
+	if !head {
+		args = append(args, fmt.Sprintf("--resources=\"$%s\"", consts.EnvWorkerResources))
+	}
+	args = append(args, "--block")
+	return []string{strings.Join(args, " ")}
+}
+
+// GetGCSPort returns the GCS/Redis port of the head which the workers connect to.
+func GetGCSPort(spec *rayv1.RaySpec) int32 {
+	return rayStartPort(spec, getPortProfile(spec.RayVersion).gcsPortName)
+}
+
+// formatParam formats the parameter of `ray start`, the value is quoted unless it
+// only contains the characters which are safe in bash.
+func formatParam(name, value string) string {
+	if value == "" {
+		return "--" + name
+	}
+	if !safeParamValue.MatchString(value) {
+		value = "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+	}
+	return fmt.Sprintf("--%s=%s", name, value)
+}
+
+// setRayStartDefaults sets the `ray start` command line and the ports of the Ray
+// container if they are not set.
+func setRayStartDefaults(template *corev1.PodTemplateSpec, containerName string,
+	spec *rayv1.RaySpec, head bool) {
+	for i := range template.Spec.Containers {
+		c := &template.Spec.Containers[i]
+		if c.Name != containerName {
+			continue
+		}
+		if len(c.Args) == 0 {
+			if len(c.Command) == 0 {
+				c.Command = defaultCmd
+			}
+			c.Args = rayStartArgs(spec, head)
+		}
+		if len(c.Ports) == 0 {
+			c.Ports = rayStartPorts(spec, head)
+		}
+	}
+}
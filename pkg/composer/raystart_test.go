@@ -0,0 +1,188 @@
+package composer
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+func TestFormatParam(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"block", "", "--block"},
+		{"num-cpus", "4", "--num-cpus=4"},
+		{"temp-dir", "/tmp/ray", "--temp-dir=/tmp/ray"},
+		{"labels", `{"zone": "a"}`, `--labels='{"zone": "a"}'`},
+		{"system-config", "it's", `--system-config='it'\''s'`},
+		{"object-store-memory", "$(rm -rf /)", "--object-store-memory='$(rm -rf /)'"},
+	}
+	for _, c := range cases {
+		if got := formatParam(c.name, c.value); got != c.want {
+			t.Errorf("formatParam(%q, %q) = %s, expected %s", c.name, c.value, got, c.want)
+		}
+	}
+}
+
+func findPort(ports []corev1.ContainerPort, name string) *corev1.ContainerPort {
+	for i := range ports {
+		if ports[i].Name == name {
+			return &ports[i]
+		}
+	}
+	return nil
+}
+
+func TestRayStartPorts(t *testing.T) {
+	cases := []struct {
+		version     string
+		gcsPortName string
+		dashboard   bool
+	}{
+		{"", consts.PortNameRedisPrimary, false},
+		{"1.10.0", consts.PortNameRedisPrimary, false},
+		{"1.11", consts.PortNameGCS, true},
+		{"2.9.0", consts.PortNameGCS, true},
+	}
+	for _, c := range cases {
+		spec := &rayv1.RaySpec{RayVersion: c.version}
+		head := rayStartPorts(spec, true)
+		if p := findPort(head, c.gcsPortName); p == nil || p.ContainerPort != consts.DefaultGCSPort {
+			t.Errorf("version %q: expected the %s port %d, got %v", c.version, c.gcsPortName,
+				consts.DefaultGCSPort, head)
+		}
+		if (findPort(head, consts.PortNameDashboard) != nil) != c.dashboard {
+			t.Errorf("version %q: expected the dashboard port %v, got %v", c.version, c.dashboard, head)
+		}
+		if SupportsDashboard(c.version) != c.dashboard {
+			t.Errorf("version %q: expected SupportsDashboard %v", c.version, c.dashboard)
+		}
+		if GetGCSPort(spec) != consts.DefaultGCSPort {
+			t.Errorf("version %q: expected the GCS port %d, got %d", c.version,
+				consts.DefaultGCSPort, GetGCSPort(spec))
+		}
+
+		// The workers do not listen on the ports of the head.
+		worker := rayStartPorts(spec, false)
+		if findPort(worker, c.gcsPortName) != nil || findPort(worker, consts.PortNameDashboard) != nil {
+			t.Errorf("version %q: expected no head ports on the workers, got %v", c.version, worker)
+		}
+		if p := findPort(worker, consts.PortNameNodeManager); p == nil || p.ContainerPort != consts.DefaultNodeManagerPort {
+			t.Errorf("version %q: expected the node manager port on the workers, got %v", c.version, worker)
+		}
+	}
+}
+
+func TestRayStartPortsOverridden(t *testing.T) {
+	spec := &rayv1.RaySpec{
+		RayVersion: "2.9.0",
+		RayStartParams: map[string]string{
+			"port":           "6390",
+			"dashboard-port": "8266",
+		},
+	}
+	ports := rayStartPorts(spec, true)
+	if p := findPort(ports, consts.PortNameGCS); p == nil || p.ContainerPort != 6390 {
+		t.Errorf("expected the GCS port 6390, got %v", ports)
+	}
+	if GetDashboardPort(spec) != 8266 {
+		t.Errorf("expected the dashboard port 8266, got %d", GetDashboardPort(spec))
+	}
+	if GetGCSPort(spec) != 6390 {
+		t.Errorf("expected the GCS port 6390, got %d", GetGCSPort(spec))
+	}
+}
+
+func TestRayStartArgs(t *testing.T) {
+	cases := []struct {
+		name    string
+		spec    *rayv1.RaySpec
+		head    bool
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "redis head",
+			spec: &rayv1.RaySpec{},
+			head: true,
+			want: []string{"ray start --head", "--redis-port=6379", "--redis-shard-ports=6380,6381",
+				"--node-ip-address=$" + consts.EnvNodeIP, "--block"},
+			notWant: []string{"--dashboard-port", "--port="},
+		},
+		{
+			name: "redis worker",
+			spec: &rayv1.RaySpec{},
+			want: []string{"--redis-address=", "--node-manager-port=12346",
+				"--resources=\"$" + consts.EnvWorkerResources + "\""},
+			notWant: []string{"--head", "--redis-port"},
+		},
+		{
+			name: "gcs head",
+			spec: &rayv1.RaySpec{RayVersion: "2.9.0"},
+			head: true,
+			want: []string{"ray start --head", "--port=6379", "--dashboard-host=0.0.0.0",
+				"--dashboard-port=8265", "--ray-client-server-port=10001"},
+			notWant: []string{"--redis-port", "--redis-shard-ports"},
+		},
+		{
+			name:    "gcs worker",
+			spec:    &rayv1.RaySpec{RayVersion: "2.9.0"},
+			want:    []string{"--address=$" + consts.EnvRayGCSAddress, "--metrics-export-port=8080"},
+			notWant: []string{"--head", "--dashboard-port", "--port="},
+		},
+		{
+			name: "params",
+			spec: &rayv1.RaySpec{
+				RayVersion: "2.9.0",
+				RayStartParams: map[string]string{
+					"num-cpus":       "0",
+					"dashboard-port": "8266",
+					"include-log":    "",
+					"block":          "false",
+				},
+			},
+			head:    true,
+			want:    []string{"--num-cpus=0", "--dashboard-port=8266", "--include-log "},
+			notWant: []string{"--dashboard-port=8265", "--block=false"},
+		},
+		{
+			name: "head only params on the workers",
+			spec: &rayv1.RaySpec{
+				RayVersion: "2.9.0",
+				RayStartParams: map[string]string{
+					"num-cpus":       "2",
+					"dashboard-port": "8266",
+				},
+			},
+			want:    []string{"--num-cpus=2"},
+			notWant: []string{"--dashboard-port"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			args := rayStartArgs(c.spec, c.head)
+			if len(args) != 1 {
+				t.Fatalf("expected a single command line, got %v", args)
+			}
+			for _, s := range c.want {
+				if !strings.Contains(args[0], s) {
+					t.Errorf("expected %q in %s", s, args[0])
+				}
+			}
+			for _, s := range c.notWant {
+				if strings.Contains(args[0], s) {
+					t.Errorf("expected no %q in %s", s, args[0])
+				}
+			}
+			if !strings.HasSuffix(args[0], " --block") {
+				t.Errorf("expected the command line to block, got %s", args[0])
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package composer
 
 import (
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -12,37 +13,137 @@ import (
 	"github.com/kubeflow/ray-operator/pkg/consts"
 )
 
+// DesiredHeadService gets the desired specification of the head service, which exposes
+// the ports of the head container.
 func (c Composer) DesiredHeadService(ray *rayv1.Ray) (*corev1.Service, error) {
 	serviceLabels := ray.Labels
 
+	annotations := map[string]string{}
+	for k, v := range ray.Spec.Head.ServiceAnnotations {
+		annotations[k] = v
+	}
+	// The type is always set, so that removing serviceType reverts the service to ClusterIP.
+	serviceType := ray.Spec.Head.ServiceType
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeClusterIP
+	}
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      getHeadName(ray.Name),
-			Namespace: ray.Namespace,
-			Labels:    serviceLabels,
+			Name:        GetHeadName(ray.Name),
+			Namespace:   ray.Namespace,
+			Labels:      serviceLabels,
+			Annotations: annotations,
 		},
 		Spec: corev1.ServiceSpec{
+			Type:     serviceType,
 			Selector: getHeadPodLabels(ray.Name),
 		},
 	}
 
-	for _, c := range ray.Spec.Head.Template.Spec.Containers {
+	// The ports are got from the desired template since they may be set by the composer.
+	for _, c := range desiredHeadTemplate(ray).Spec.Containers {
 		if c.Name == consts.ContainerRayHead {
-			for i, p := range c.Ports {
+			for _, p := range c.Ports {
 				name := p.Name
 				if name == "" {
-					name = fmt.Sprintf("copy-from-%d", i)
+					protocol := p.Protocol
+					if protocol == "" {
+						protocol = corev1.ProtocolTCP
+					}
+					name = fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), p.ContainerPort)
 				}
 				service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
 					Name:       name,
+					Protocol:   p.Protocol,
 					Port:       p.ContainerPort,
 					TargetPort: intstr.FromInt(int(p.ContainerPort)),
 				})
 			}
 		}
 	}
+	// The annotations are hashed with the spec, thus the changes of them update the service.
+	if err := setSpecHash(&service.ObjectMeta, []interface{}{
+		ray.Spec.Head.ServiceAnnotations, service.Spec,
+	}); err != nil {
+		return nil, err
+	}
+	if err := controllerutil.SetControllerReference(ray, service, c.scheme); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// DesiredHeadGoverningService gets the desired specification of the headless service
+// which governs the head StatefulSet, thus the head pod has the stable DNS name
+// {ray.name}-head-0.{ray.name}-head-headless. The head is published before it is ready
+// since the workers wait for it while it is starting.
+func (c Composer) DesiredHeadGoverningService(ray *rayv1.Ray) (*corev1.Service, error) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetHeadGoverningServiceName(ray.Name),
+			Namespace: ray.Namespace,
+			Labels:    ray.Labels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:                corev1.ClusterIPNone,
+			Selector:                 getHeadPodLabels(ray.Name),
+			PublishNotReadyAddresses: true,
+		},
+	}
+	if err := setSpecHash(&service.ObjectMeta, service.Spec); err != nil {
+		return nil, err
+	}
+	if err := controllerutil.SetControllerReference(ray, service, c.scheme); err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// DesiredWorkerService gets the desired specification of the headless service which
+// selects all worker pods of the Ray, thus every worker has a DNS record for the peer
+// discovery. The workers are published before they are ready since they register with
+// the head while starting.
+func (c Composer) DesiredWorkerService(ray *rayv1.Ray) (*corev1.Service, error) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetWorkerServiceName(ray.Name),
+			Namespace: ray.Namespace,
+			Labels:    ray.Labels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:                corev1.ClusterIPNone,
+			Selector:                 getWorkerServiceSelector(ray.Name),
+			PublishNotReadyAddresses: true,
+		},
+	}
+	if err := setSpecHash(&service.ObjectMeta, service.Spec); err != nil {
+		return nil, err
+	}
 	if err := controllerutil.SetControllerReference(ray, service, c.scheme); err != nil {
 		return nil, err
 	}
 	return service, nil
 }
+
+func getWorkerServiceSelector(rayName string) map[string]string {
+	return map[string]string{
+		consts.LabelRay:         rayName,
+		consts.LabelRayNodeType: consts.RayNodeTypeWorker,
+	}
+}
+
+// GetHeadGoverningServiceName returns the name of the headless service which governs
+// the head StatefulSet.
+func GetHeadGoverningServiceName(rayName string) string {
+	return fmt.Sprintf("%s-head-headless", rayName)
+}
+
+// GetWorkerServiceName returns the name of the headless service of the workers.
+func GetWorkerServiceName(rayName string) string {
+	return fmt.Sprintf("%s-workers", rayName)
+}
+
+// GetServiceFQDN returns the DNS name of the service qualified with the namespace.
+func GetServiceFQDN(name, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc", name, namespace)
+}
@@ -0,0 +1,92 @@
+package composer
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+func TestDesiredHeadServiceType(t *testing.T) {
+	cases := []struct {
+		serviceType corev1.ServiceType
+		expected    corev1.ServiceType
+	}{
+		{"", corev1.ServiceTypeClusterIP},
+		{corev1.ServiceTypeNodePort, corev1.ServiceTypeNodePort},
+		{corev1.ServiceTypeLoadBalancer, corev1.ServiceTypeLoadBalancer},
+	}
+	for _, c := range cases {
+		ray := newTestRay()
+		ray.Spec.Head.ServiceType = c.serviceType
+		service, err := newTestComposer().DesiredHeadService(ray)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if service.Spec.Type != c.expected {
+			t.Errorf("serviceType %q: expected the type %s, got %s", c.serviceType, c.expected, service.Spec.Type)
+		}
+	}
+}
+
+func TestDesiredWorkerService(t *testing.T) {
+	ray := newTestRay()
+	service, err := newTestComposer().DesiredWorkerService(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if service.Name != "test-workers" || service.Namespace != ray.Namespace {
+		t.Errorf("expected the service default/test-workers, got %s/%s", service.Namespace, service.Name)
+	}
+	if service.Spec.ClusterIP != corev1.ClusterIPNone {
+		t.Errorf("expected a headless service, got the cluster IP %q", service.Spec.ClusterIP)
+	}
+	if !service.Spec.PublishNotReadyAddresses {
+		t.Error("expected the workers to be published before they are ready")
+	}
+	expected := map[string]string{
+		consts.LabelRay:         ray.Name,
+		consts.LabelRayNodeType: consts.RayNodeTypeWorker,
+	}
+	if !reflect.DeepEqual(service.Spec.Selector, expected) {
+		t.Errorf("expected the selector %v, got %v", expected, service.Spec.Selector)
+	}
+	if len(service.OwnerReferences) != 1 || service.OwnerReferences[0].Name != ray.Name {
+		t.Errorf("expected the service to be owned by the Ray, got %v", service.OwnerReferences)
+	}
+}
+
+func TestDesiredWorkerServiceSelectsWorkers(t *testing.T) {
+	ray := newTestRay()
+	c := newTestComposer()
+	service, err := c.DesiredWorkerService(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	head, err := c.DesiredHead(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	workers, err := c.DesiredWorkers(ray)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !matchSelector(service.Spec.Selector, workers[0].Spec.Template.Labels) {
+		t.Errorf("expected the service to select the workers, got the selector %v and the labels %v",
+			service.Spec.Selector, workers[0].Spec.Template.Labels)
+	}
+	if matchSelector(service.Spec.Selector, head.Spec.Template.Labels) {
+		t.Errorf("expected the service not to select the head, got the labels %v", head.Spec.Template.Labels)
+	}
+}
+
+func matchSelector(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
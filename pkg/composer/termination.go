@@ -0,0 +1,60 @@
+package composer
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// DesiredPreDeleteHook gets the desired specification of the Job which runs before the
+// head is deleted in the graceful termination.
+func (c Composer) DesiredPreDeleteHook(ray *rayv1.Ray) (*batchv1.Job, error) {
+	if ray.Spec.Termination == nil || ray.Spec.Termination.PreDeleteHook == nil {
+		return nil, fmt.Errorf("the pre-delete hook of the Ray %s is not specified", ray.Name)
+	}
+
+	labels := map[string]string{
+		consts.LabelRay: ray.Name,
+	}
+	spec := ray.Spec.Termination.PreDeleteHook.DeepCopy()
+	if spec.Template.Labels == nil {
+		spec.Template.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		spec.Template.Labels[k] = v
+	}
+	if spec.Template.Spec.RestartPolicy == "" {
+		spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	for i := range spec.Template.Spec.Containers {
+		spec.Template.Spec.Containers[i].Env = append(spec.Template.Spec.Containers[i].Env,
+			corev1.EnvVar{
+				Name:  consts.EnvRayHeadService,
+				Value: GetHeadName(ray.Name),
+			})
+	}
+
+	hook := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetPreDeleteHookName(ray.Name),
+			Namespace: ray.Namespace,
+			Labels:    labels,
+		},
+		Spec: *spec,
+	}
+	if err := controllerutil.SetControllerReference(ray, hook, c.scheme); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// GetPreDeleteHookName returns the name of the pre-delete hook Job.
+func GetPreDeleteHookName(rayName string) string {
+	return fmt.Sprintf("%s-pre-delete", rayName)
+}
@@ -4,17 +4,77 @@ const (
 	EventNormal  = "Normal"
 	EventWarning = "Warning"
 
-	ReasonValidationFailed = "ValidationFailedOrNotImplemented"
+	ReasonValidationFailed = "ValidationFailed"
 	ReasonCreate           = "SuccessfullyCreate"
 	ReasonUpdate           = "SuccessfullyUpdate"
+	ReasonDelete           = "SuccessfullyDelete"
+	ReasonScaleUp          = "ScaleUp"
+	ReasonScaleDown        = "ScaleDown"
+	ReasonScaleFailed      = "ScaleFailed"
+	ReasonServeDeployed    = "ServeDeployed"
+	ReasonServeUnavailable = "ServeUnavailable"
+	ReasonSwitched         = "Switched"
+	ReasonComposeFailed    = "ComposeFailed"
+	ReasonReconcileFailed  = "ReconcileFailed"
+	ReasonHeadReachable    = "HeadReachable"
+	ReasonHeadUnreachable  = "HeadUnreachable"
+	ReasonHeadRecovering   = "HeadRecovering"
+	ReasonExternalRedis    = "ExternalRedis"
+	ReasonDisabled         = "Disabled"
+	ReasonWorkersJoined    = "WorkersJoined"
+	ReasonWorkersMissing   = "WorkersMissing"
 
-	LabelRayWorker = "ray-worker"
-	LabelRayHead   = "ray-head"
-	LabelRay       = "ray"
+	LabelRayWorker      = "ray-worker"
+	LabelRayWorkerGroup = "ray-worker-group"
+	LabelRayHead        = "ray-head"
+	LabelRay            = "ray"
+	LabelRayJob         = "ray-job"
+	LabelRayService     = "ray-service"
+	LabelRayNodeType    = "ray-node-type"
+	RayNodeTypeWorker   = "worker"
 
-	EnvNodeIP         = "RAY_NODE_IP"
-	FieldPathPodIP    = "status.podIP"
-	EnvRayHeadService = "RAY_HEAD_SERVICE"
+	AnnotationSpecHash = "ray.kubeflow.org/spec-hash"
+	// AnnotationExternalReplicas is set to "true" on the Ray and its worker deployments
+	// if the replicas of the workers are managed by another controller.
+	AnnotationExternalReplicas = "ray.kubeflow.org/external-replicas"
+	// AnnotationManagedLabels and AnnotationManagedAnnotations list the keys of the labels
+	// and annotations set by the operator on the objects of the Ray, separated by commas.
+	// The keys which are not desired anymore are deleted when the objects are updated.
+	AnnotationManagedLabels      = "ray.kubeflow.org/managed-labels"
+	AnnotationManagedAnnotations = "ray.kubeflow.org/managed-annotations"
 
-	ContainerRayHead = "ray-head"
+	AnnotationVolcanoGroupName       = "scheduling.k8s.io/group-name"
+	LabelCoschedulingPodGroup        = "scheduling.x-k8s.io/pod-group"
+	DefaultVolcanoSchedulerName      = "volcano"
+	DefaultCoschedulingSchedulerName = "scheduler-plugins-scheduler"
+
+	EnvNodeIP               = "RAY_NODE_IP"
+	FieldPathPodIP          = "status.podIP"
+	EnvRayHeadService       = "RAY_HEAD_SERVICE"
+	EnvRayHeadServiceFQDN   = "RAY_HEAD_SERVICE_FQDN"
+	EnvRayGCSAddress        = "RAY_GCS_ADDRESS"
+	EnvRayWorkerServiceFQDN = "RAY_WORKER_SERVICE_FQDN"
+	EnvWorkerResources      = "RAY_WORKER_RESOURCES"
+
+	EnvRayRedisAddress             = "RAY_REDIS_ADDRESS"
+	EnvRedisPassword               = "REDIS_PASSWORD"
+	EnvRayExternalStorageNamespace = "RAY_external_storage_namespace"
+	EnvRayGCSReconnectTimeout      = "RAY_gcs_rpc_server_reconnect_timeout_s"
+
+	ContainerRayHead      = "ray-head"
+	ContainerRayWorker    = "ray-worker"
+	ContainerRaySubmitter = "ray-submitter"
+	ContainerWaitForHead  = "wait-for-head"
+
+	PortNameGCS          = "gcs-server"
+	PortNameRedisPrimary = "redis-primary"
+	PortNameDashboard    = "dashboard"
+	PortNameClient       = "client"
+	PortNameNodeManager  = "node-manager"
+
+	DefaultGCSPort         = 6379
+	DefaultNodeManagerPort = 12346
+	DefaultDashboardPort   = 8265
+	DefaultClientPort      = 10001
+	DefaultServePort       = 8000
 )
@@ -0,0 +1,194 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+)
+
+const (
+	metricsNamespace = "ray_operator"
+
+	// ComponentHead and ComponentWorker are the values of the component label.
+	ComponentHead   = "head"
+	ComponentWorker = "worker"
+)
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of the reconciliation of the Ray in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"namespace", "ray"})
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of the failed reconciliations of the Ray.",
+	}, []string{"namespace", "ray"})
+	desiredReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "ray_desired_replicas",
+		Help:      "Number of the desired replicas of the head or the worker group.",
+	}, []string{"namespace", "ray", "component", "group"})
+	readyReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "ray_ready_replicas",
+		Help:      "Number of the ready replicas of the head or the worker group.",
+	}, []string{"namespace", "ray", "component", "group"})
+	availableReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "ray_available_replicas",
+		Help:      "Number of the available replicas of the head or the worker group.",
+	}, []string{"namespace", "ray", "component", "group"})
+	condition = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "ray_condition",
+		Help: "The condition of the Ray or the worker group. It is 1 for the current status " +
+			"of the condition and 0 for the others.",
+	}, []string{"namespace", "ray", "group", "condition", "status"})
+	timeToHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "ray_time_to_healthy_seconds",
+		Help:      "Seconds from the start time of the Ray to the first time it is healthy.",
+	}, []string{"namespace", "ray"})
+
+	conditionStatuses = []corev1.ConditionStatus{
+		corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown,
+	}
+
+	// recorded keeps the series of the gauges recorded for every Ray, thus the
+	// stale series are deleted when the status is changed or the Ray is deleted.
+	recorded = map[string]map[string]series{}
+	// healthy keeps the Rays whose time to healthy is recorded.
+	healthy = map[string]bool{}
+	lock    sync.Mutex
+)
+
+// series is a series of a gauge vector.
+type series struct {
+	vec    *prometheus.GaugeVec
+	labels prometheus.Labels
+}
+
+func init() {
+	metrics.Registry.MustRegister(reconcileDuration, reconcileErrors,
+		desiredReplicas, readyReplicas, availableReplicas, condition, timeToHealthy)
+}
+
+// Replicas is the number of the replicas of the head or a worker group.
+type Replicas struct {
+	// Component is ComponentHead or ComponentWorker.
+	Component string
+	// Group is the name of the worker group, it is empty for the head.
+	Group     string
+	Desired   int32
+	Ready     int32
+	Available int32
+}
+
+// ObserveReconcile records the duration of the reconciliation since start, and
+// counts the error if the reconciliation failed.
+func ObserveReconcile(namespace, name string, start time.Time, failed bool) {
+	reconcileDuration.WithLabelValues(namespace, name).Observe(time.Since(start).Seconds())
+	if failed {
+		reconcileErrors.WithLabelValues(namespace, name).Inc()
+	}
+}
+
+// RecordStatus records the replicas and the conditions of the Ray, and the time to
+// healthy when the Ray is healthy for the first time. The series recorded before but
+// not in the current status, e.g. the removed worker groups, are deleted.
+func RecordStatus(ray *rayv1.Ray, replicas []Replicas) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	key := ray.Namespace + "/" + ray.Name
+	current := map[string]series{}
+	set := func(vec *prometheus.GaugeVec, labels prometheus.Labels, value float64) {
+		vec.With(labels).Set(value)
+		current[seriesKey(vec, labels)] = series{vec: vec, labels: labels}
+	}
+
+	for _, r := range replicas {
+		labels := prometheus.Labels{
+			"namespace": ray.Namespace,
+			"ray":       ray.Name,
+			"component": r.Component,
+			"group":     r.Group,
+		}
+		set(desiredReplicas, labels, float64(r.Desired))
+		set(readyReplicas, labels, float64(r.Ready))
+		set(availableReplicas, labels, float64(r.Available))
+	}
+
+	recordConditions := func(group string, conditions []rayv1.RayCondition) {
+		for _, c := range conditions {
+			for _, s := range conditionStatuses {
+				value := 0.0
+				if c.Status == s {
+					value = 1
+				}
+				set(condition, prometheus.Labels{
+					"namespace": ray.Namespace,
+					"ray":       ray.Name,
+					"group":     group,
+					"condition": string(c.Type),
+					"status":    string(s),
+				}, value)
+			}
+		}
+	}
+	recordConditions("", ray.Status.Conditions)
+	for _, g := range ray.Status.WorkerGroups {
+		recordConditions(g.Name, g.Conditions)
+	}
+
+	for k, s := range recorded[key] {
+		if _, ok := current[k]; !ok {
+			s.vec.Delete(s.labels)
+		}
+	}
+	recorded[key] = current
+
+	// The time to healthy is recorded once for every Ray. If the operator is restarted,
+	// it is recorded from the last transition of the Health condition.
+	if healthy[key] || ray.Status.StartTime == nil {
+		return
+	}
+	for _, c := range ray.Status.Conditions {
+		if c.Type == rayv1.RayHealth && c.Status == corev1.ConditionTrue {
+			timeToHealthy.WithLabelValues(ray.Namespace, ray.Name).Set(
+				c.LastTransitionTime.Sub(ray.Status.StartTime.Time).Seconds())
+			healthy[key] = true
+		}
+	}
+}
+
+// Forget deletes all series of the deleted Ray.
+func Forget(namespace, name string) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	key := namespace + "/" + name
+	for _, s := range recorded[key] {
+		s.vec.Delete(s.labels)
+	}
+	delete(recorded, key)
+	delete(healthy, key)
+	reconcileDuration.DeleteLabelValues(namespace, name)
+	reconcileErrors.DeleteLabelValues(namespace, name)
+	timeToHealthy.DeleteLabelValues(namespace, name)
+}
+
+// seriesKey identifies the series by the gauge vector and the labels, the labels are
+// printed in the sorted order.
+func seriesKey(vec *prometheus.GaugeVec, labels prometheus.Labels) string {
+	return fmt.Sprintf("%p%v", vec, labels)
+}
@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+)
+
+func newTestRay(name string) *rayv1.Ray {
+	return &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+	}
+}
+
+// gaugeValue returns the value of the gauge series with the given labels in the
+// registry of controller-runtime, and whether the series exists.
+func gaugeValue(t *testing.T, name string, labels map[string]string) (float64, bool) {
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather the metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if matchLabels(m.GetLabel(), labels) {
+				return m.GetGauge().GetValue(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func matchLabels(pairs []*dto.LabelPair, labels map[string]string) bool {
+	matched := 0
+	for _, pair := range pairs {
+		v, ok := labels[pair.GetName()]
+		if !ok {
+			continue
+		}
+		if v != pair.GetValue() {
+			return false
+		}
+		matched++
+	}
+	return matched == len(labels)
+}
+
+func conditionLabels(ray string, status corev1.ConditionStatus) map[string]string {
+	return map[string]string{
+		"namespace": "default",
+		"ray":       ray,
+		"group":     "",
+		"condition": string(rayv1.RayHealth),
+		"status":    string(status),
+	}
+}
+
+func TestRecordStatusConditionTransition(t *testing.T) {
+	ray := newTestRay("transition")
+	ray.Status.Conditions = []rayv1.RayCondition{
+		{Type: rayv1.RayHealth, Status: corev1.ConditionFalse},
+	}
+	RecordStatus(ray, nil)
+	if v, _ := gaugeValue(t, "ray_operator_ray_condition", conditionLabels(ray.Name, corev1.ConditionFalse)); v != 1 {
+		t.Errorf("expected the False status to be 1, got %v", v)
+	}
+	if v, _ := gaugeValue(t, "ray_operator_ray_condition", conditionLabels(ray.Name, corev1.ConditionTrue)); v != 0 {
+		t.Errorf("expected the True status to be 0, got %v", v)
+	}
+
+	ray.Status.Conditions[0].Status = corev1.ConditionTrue
+	RecordStatus(ray, nil)
+	if v, _ := gaugeValue(t, "ray_operator_ray_condition", conditionLabels(ray.Name, corev1.ConditionFalse)); v != 0 {
+		t.Errorf("expected the False status to be 0, got %v", v)
+	}
+	if v, _ := gaugeValue(t, "ray_operator_ray_condition", conditionLabels(ray.Name, corev1.ConditionTrue)); v != 1 {
+		t.Errorf("expected the True status to be 1, got %v", v)
+	}
+}
+
+func TestRecordStatusDeletesStaleSeries(t *testing.T) {
+	ray := newTestRay("stale")
+	cpu := map[string]string{"namespace": "default", "ray": ray.Name, "component": ComponentWorker, "group": "cpu"}
+	RecordStatus(ray, []Replicas{
+		{Component: ComponentHead, Desired: 1},
+		{Component: ComponentWorker, Group: "cpu", Desired: 2, Ready: 1},
+	})
+	if v, ok := gaugeValue(t, "ray_operator_ray_desired_replicas", cpu); !ok || v != 2 {
+		t.Errorf("expected 2 desired replicas of the group cpu, got %v, %v", v, ok)
+	}
+
+	// The worker group is removed.
+	RecordStatus(ray, []Replicas{
+		{Component: ComponentHead, Desired: 1},
+	})
+	if _, ok := gaugeValue(t, "ray_operator_ray_desired_replicas", cpu); ok {
+		t.Error("expected the series of the removed group to be deleted")
+	}
+	head := map[string]string{"namespace": "default", "ray": ray.Name, "component": ComponentHead}
+	if _, ok := gaugeValue(t, "ray_operator_ray_desired_replicas", head); !ok {
+		t.Error("expected the series of the head to be kept")
+	}
+}
+
+func TestForget(t *testing.T) {
+	ray := newTestRay("forget")
+	start := metav1.NewTime(time.Now())
+	ray.Status.StartTime = &start
+	ray.Status.Conditions = []rayv1.RayCondition{
+		{Type: rayv1.RayHealth, Status: corev1.ConditionTrue, LastTransitionTime: start},
+	}
+	RecordStatus(ray, []Replicas{{Component: ComponentHead, Desired: 1}})
+
+	Forget(ray.Namespace, ray.Name)
+	selector := map[string]string{"namespace": "default", "ray": ray.Name}
+	for _, name := range []string{
+		"ray_operator_ray_desired_replicas",
+		"ray_operator_ray_condition",
+		"ray_operator_ray_time_to_healthy_seconds",
+	} {
+		if _, ok := gaugeValue(t, name, selector); ok {
+			t.Errorf("expected the series of %s to be deleted", name)
+		}
+	}
+}
+
+func TestRecordStatusTimeToHealthyOnce(t *testing.T) {
+	ray := newTestRay("healthy")
+	selector := map[string]string{"namespace": "default", "ray": ray.Name}
+	start := metav1.NewTime(time.Now())
+	ray.Status.StartTime = &start
+	ray.Status.Conditions = []rayv1.RayCondition{
+		{Type: rayv1.RayHealth, Status: corev1.ConditionFalse, LastTransitionTime: start},
+	}
+	RecordStatus(ray, nil)
+	if _, ok := gaugeValue(t, "ray_operator_ray_time_to_healthy_seconds", selector); ok {
+		t.Error("expected the time to healthy not to be recorded before the Ray is healthy")
+	}
+
+	ray.Status.Conditions[0].Status = corev1.ConditionTrue
+	ray.Status.Conditions[0].LastTransitionTime = metav1.NewTime(start.Add(10 * time.Second))
+	RecordStatus(ray, nil)
+	if v, _ := gaugeValue(t, "ray_operator_ray_time_to_healthy_seconds", selector); v != 10 {
+		t.Errorf("expected 10 seconds to healthy, got %v", v)
+	}
+
+	// The Ray becomes healthy again after it is degraded.
+	ray.Status.Conditions[0].LastTransitionTime = metav1.NewTime(start.Add(60 * time.Second))
+	RecordStatus(ray, nil)
+	if v, _ := gaugeValue(t, "ray_operator_ray_time_to_healthy_seconds", selector); v != 10 {
+		t.Errorf("expected the time to healthy to be kept at 10 seconds, got %v", v)
+	}
+}
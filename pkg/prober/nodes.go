@@ -0,0 +1,109 @@
+package prober
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+)
+
+const (
+	nodesPath = "/nodes?view=summary"
+
+	// nodeAlive is the state of the alive nodes reported by the raylet.
+	nodeAlive = "ALIVE"
+
+	// The resource names used by Ray.
+	resourceCPU    = "CPU"
+	resourceGPU    = "GPU"
+	resourceMemory = "memory"
+
+	resourceNvidiaGPU corev1.ResourceName = "nvidia.com/gpu"
+)
+
+// NodeSource gets the nodes registered with the head of the Ray.
+type NodeSource interface {
+	GetNodes(ray *rayv1.Ray) (*rayv1.ClusterResourcesStatus, error)
+}
+
+// HTTPNodeSource gets the nodes from the dashboard of the head through the head service.
+// The dashboard port is got from the Ray.
+type HTTPNodeSource struct {
+	Client *http.Client
+}
+
+// NewHTTPNodeSource returns a new HTTPNodeSource.
+func NewHTTPNodeSource(timeout time.Duration) NodeSource {
+	return &HTTPNodeSource{
+		Client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// nodesSummary is the response of the nodes API of the dashboard.
+type nodesSummary struct {
+	Result bool   `json:"result"`
+	Msg    string `json:"msg"`
+	Data   struct {
+		Summary []struct {
+			Raylet struct {
+				State          string             `json:"state"`
+				IsHeadNode     bool               `json:"isHeadNode"`
+				ResourcesTotal map[string]float64 `json:"resourcesTotal"`
+			} `json:"raylet"`
+		} `json:"summary"`
+	} `json:"data"`
+}
+
+// GetNodes gets the alive nodes and their total resources from the dashboard of the head.
+func (s HTTPNodeSource) GetNodes(ray *rayv1.Ray) (*rayv1.ClusterResourcesStatus, error) {
+	url := fmt.Sprintf("http://%s.%s.svc:%d%s", composer.GetHeadName(ray.Name),
+		ray.Namespace, composer.GetDashboardPort(&ray.Spec), nodesPath)
+	resp, err := s.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the nodes from %s: %s", url, resp.Status)
+	}
+
+	summary := &nodesSummary{}
+	if err := json.NewDecoder(resp.Body).Decode(summary); err != nil {
+		return nil, err
+	}
+	if !summary.Result {
+		return nil, fmt.Errorf("failed to get the nodes from %s: %s", url, summary.Msg)
+	}
+	return parseNodesSummary(summary), nil
+}
+
+func parseNodesSummary(summary *nodesSummary) *rayv1.ClusterResourcesStatus {
+	var cpu, gpu, memory float64
+	status := &rayv1.ClusterResourcesStatus{}
+	for _, node := range summary.Data.Summary {
+		if node.Raylet.State != nodeAlive {
+			continue
+		}
+		status.AliveNodes++
+		if !node.Raylet.IsHeadNode {
+			status.AliveWorkers++
+		}
+		cpu += node.Raylet.ResourcesTotal[resourceCPU]
+		gpu += node.Raylet.ResourcesTotal[resourceGPU]
+		memory += node.Raylet.ResourcesTotal[resourceMemory]
+	}
+	status.Resources = corev1.ResourceList{
+		corev1.ResourceCPU:    *resource.NewMilliQuantity(int64(cpu*1000), resource.DecimalSI),
+		corev1.ResourceMemory: *resource.NewQuantity(int64(memory), resource.BinarySI),
+		resourceNvidiaGPU:     *resource.NewQuantity(int64(gpu), resource.DecimalSI),
+	}
+	return status
+}
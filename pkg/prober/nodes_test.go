@@ -0,0 +1,42 @@
+package prober
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const testNodesSummary = `{
+  "result": true,
+  "msg": "Node summary fetched.",
+  "data": {
+    "summary": [
+      {"raylet": {"state": "ALIVE", "isHeadNode": true, "resourcesTotal": {"CPU": 2, "memory": 4294967296}}},
+      {"raylet": {"state": "ALIVE", "isHeadNode": false, "resourcesTotal": {"CPU": 1.5, "GPU": 1, "memory": 2147483648}}},
+      {"raylet": {"state": "DEAD", "isHeadNode": false, "resourcesTotal": {"CPU": 4, "GPU": 1, "memory": 2147483648}}}
+    ]
+  }
+}`
+
+func TestParseNodesSummary(t *testing.T) {
+	summary := &nodesSummary{}
+	if err := json.Unmarshal([]byte(testNodesSummary), summary); err != nil {
+		t.Fatalf("failed to decode the nodes summary: %v", err)
+	}
+	status := parseNodesSummary(summary)
+	if status.AliveNodes != 2 || status.AliveWorkers != 1 {
+		t.Errorf("expected 2 alive nodes and 1 alive worker, got %d and %d",
+			status.AliveNodes, status.AliveWorkers)
+	}
+	for name, expected := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    "3500m",
+		corev1.ResourceMemory: "6Gi",
+		resourceNvidiaGPU:     "1",
+	} {
+		actual := status.Resources[name]
+		if actual.String() != expected {
+			t.Errorf("expected %s %s, got %s", name, expected, actual.String())
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package prober
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+const (
+	// SyncPeriod is the period the head of the Ray is probed.
+	SyncPeriod = 30 * time.Second
+)
+
+// Interface probes whether the Ray processes of the head are reachable.
+type Interface interface {
+	// ProbeHead returns an error if the GCS/Redis port or the dashboard of the head
+	// cannot be reached through the head service.
+	ProbeHead(ray *rayv1.Ray, service *corev1.Service) error
+}
+
+// TCPProber dials the GCS/Redis port and the dashboard port of the head service.
+// The dashboard is probed only if the head service exposes its port, while the
+// GCS/Redis port is required since the workers cannot join the cluster without it.
+type TCPProber struct {
+	GCSPort int
+	Timeout time.Duration
+	// Host returns the host which the ports are dialed on, it is the DNS name of
+	// the head service by default.
+	Host func(service *corev1.Service) string
+}
+
+// NewTCPProber returns a new TCPProber.
+func NewTCPProber(gcsPort int, timeout time.Duration) Interface {
+	return &TCPProber{
+		GCSPort: gcsPort,
+		Timeout: timeout,
+		Host:    serviceHost,
+	}
+}
+
+// ProbeHead dials the GCS/Redis port and the dashboard port of the head service. The
+// ports named by the operator are preferred, since they may be changed by the
+// parameters of `ray start`. The dashboard port falls back to the one of the Ray.
+func (p TCPProber) ProbeHead(ray *rayv1.Ray, service *corev1.Service) error {
+	gcsPort := namedPort(service, p.GCSPort, consts.PortNameGCS, consts.PortNameRedisPrimary)
+	if !hasPort(service, gcsPort) {
+		return fmt.Errorf("the head service %s does not expose the GCS port %d",
+			service.Name, gcsPort)
+	}
+	ports := []int{gcsPort}
+	dashboardPort := namedPort(service, int(composer.GetDashboardPort(&ray.Spec)), consts.PortNameDashboard)
+	if hasPort(service, dashboardPort) {
+		ports = append(ports, dashboardPort)
+	}
+
+	host := serviceHost(service)
+	if p.Host != nil {
+		host = p.Host(service)
+	}
+	for _, port := range ports {
+		addr := net.JoinHostPort(host, strconv.Itoa(port))
+		conn, err := net.DialTimeout("tcp", addr, p.Timeout)
+		if err != nil {
+			return fmt.Errorf("failed to reach the head at %s: %v", addr, err)
+		}
+		conn.Close()
+	}
+	return nil
+}
+
+func serviceHost(service *corev1.Service) string {
+	return fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace)
+}
+
+// namedPort returns the port of the service which has one of the names, or the
+// default port if there is no such port.
+func namedPort(service *corev1.Service, defaultPort int, names ...string) int {
+	for _, name := range names {
+		for _, p := range service.Spec.Ports {
+			if p.Name == name {
+				return int(p.Port)
+			}
+		}
+	}
+	return defaultPort
+}
+
+func hasPort(service *corev1.Service, port int) bool {
+	for _, p := range service.Spec.Ports {
+		if int(p.Port) == port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package prober
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+// newFakeServer starts a local TCP server which accepts and closes the connections,
+// and returns its port.
+func newFakeServer(t *testing.T) (net.Listener, int) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return l, l.Addr().(*net.TCPAddr).Port
+}
+
+// newClosedPort returns a local port which nothing listens on.
+func newClosedPort(t *testing.T) int {
+	l, port := newFakeServer(t)
+	l.Close()
+	return port
+}
+
+func newTestProber(gcsPort int) *TCPProber {
+	return &TCPProber{
+		GCSPort: gcsPort,
+		Timeout: time.Second,
+		Host: func(*corev1.Service) string {
+			return "127.0.0.1"
+		},
+	}
+}
+
+// newTestRay returns a Ray whose dashboard listens on the port.
+func newTestRay(dashboardPort int) *rayv1.Ray {
+	return &rayv1.Ray{
+		Spec: rayv1.RaySpec{
+			RayVersion: "2.9.0",
+			RayStartParams: map[string]string{
+				"dashboard-port": strconv.Itoa(dashboardPort),
+			},
+		},
+	}
+}
+
+func newTestService(ports ...int) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-head",
+			Namespace: "default",
+		},
+	}
+	for _, p := range ports {
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{Port: int32(p)})
+	}
+	return service
+}
+
+func TestProbeHeadReachable(t *testing.T) {
+	gcs, gcsPort := newFakeServer(t)
+	defer gcs.Close()
+	dashboard, dashboardPort := newFakeServer(t)
+	defer dashboard.Close()
+
+	p := newTestProber(gcsPort)
+	if err := p.ProbeHead(newTestRay(dashboardPort), newTestService(gcsPort, dashboardPort)); err != nil {
+		t.Errorf("expected the head to be reachable, got %v", err)
+	}
+}
+
+func TestProbeHeadGCSUnreachable(t *testing.T) {
+	dashboard, dashboardPort := newFakeServer(t)
+	defer dashboard.Close()
+	gcsPort := newClosedPort(t)
+
+	p := newTestProber(gcsPort)
+	if err := p.ProbeHead(newTestRay(dashboardPort), newTestService(gcsPort, dashboardPort)); err == nil {
+		t.Errorf("expected an error when the GCS port is closed")
+	}
+}
+
+func TestProbeHeadDashboardUnreachable(t *testing.T) {
+	gcs, gcsPort := newFakeServer(t)
+	defer gcs.Close()
+	dashboardPort := newClosedPort(t)
+
+	p := newTestProber(gcsPort)
+	if err := p.ProbeHead(newTestRay(dashboardPort), newTestService(gcsPort, dashboardPort)); err == nil {
+		t.Errorf("expected an error when the dashboard port is closed")
+	}
+	// The dashboard is not probed if the head service does not expose it.
+	if err := p.ProbeHead(newTestRay(dashboardPort), newTestService(gcsPort)); err != nil {
+		t.Errorf("expected the head to be reachable without the dashboard, got %v", err)
+	}
+}
+
+func TestProbeHeadGCSPortNotExposed(t *testing.T) {
+	dashboard, dashboardPort := newFakeServer(t)
+	defer dashboard.Close()
+
+	p := newTestProber(newClosedPort(t))
+	if err := p.ProbeHead(newTestRay(dashboardPort), newTestService(dashboardPort)); err == nil {
+		t.Errorf("expected an error when the head service does not expose the GCS port")
+	}
+}
+
+func TestProbeHeadNamedDashboardPort(t *testing.T) {
+	gcs, gcsPort := newFakeServer(t)
+	defer gcs.Close()
+	dashboard, dashboardPort := newFakeServer(t)
+	defer dashboard.Close()
+
+	// The named port of the head service is preferred to the port of the Ray.
+	service := newTestService(gcsPort)
+	service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+		Name: consts.PortNameDashboard,
+		Port: int32(dashboardPort),
+	})
+	p := newTestProber(gcsPort)
+	if err := p.ProbeHead(newTestRay(newClosedPort(t)), service); err != nil {
+		t.Errorf("expected the named dashboard port to be probed, got %v", err)
+	}
+}
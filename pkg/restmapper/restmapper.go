@@ -0,0 +1,145 @@
+package restmapper
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// DefaultReloadInterval is the minimal interval between the reloads of the mappings,
+	// so that the requests of a missing kind do not flood the discovery API.
+	DefaultReloadInterval = 10 * time.Second
+)
+
+// Lazy is a meta.RESTMapper which reloads the mappings from the discovery API when a
+// kind or a resource is not found, e.g. the PodGroup whose CRD is installed after the
+// manager starts. The mapper built by the manager by default never sees such a CRD.
+type Lazy struct {
+	mu     sync.RWMutex
+	mapper meta.RESTMapper
+	// lastReload is the time the mappings are reloaded.
+	lastReload time.Time
+
+	// ReloadInterval is the minimal interval between the reloads.
+	ReloadInterval time.Duration
+	// NewMapper loads the mappings.
+	NewMapper func() (meta.RESTMapper, error)
+	// Now returns the current time.
+	Now func() time.Time
+}
+
+var _ meta.RESTMapper = &Lazy{}
+
+// NewLazy returns a new Lazy which loads the mappings from the discovery API of the config.
+// It can be used as the MapperProvider of the manager.
+func NewLazy(c *rest.Config) (meta.RESTMapper, error) {
+	return newLazy(func() (meta.RESTMapper, error) {
+		return apiutil.NewDiscoveryRESTMapper(c)
+	})
+}
+
+func newLazy(newMapper func() (meta.RESTMapper, error)) (*Lazy, error) {
+	l := &Lazy{
+		ReloadInterval: DefaultReloadInterval,
+		NewMapper:      newMapper,
+		Now:            time.Now,
+	}
+	mapper, err := newMapper()
+	if err != nil {
+		return nil, err
+	}
+	l.mapper = mapper
+	l.lastReload = l.Now()
+	return l, nil
+}
+
+// delegate returns the current mappings.
+func (l *Lazy) delegate() meta.RESTMapper {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.mapper
+}
+
+// reloadOnNoMatch reloads the mappings if the error is a no match error and the mappings
+// are not reloaded in the interval. It returns true if the mappings are reloaded.
+func (l *Lazy) reloadOnNoMatch(err error) bool {
+	if !meta.IsNoMatchError(err) {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.Now().Sub(l.lastReload) < l.ReloadInterval {
+		return false
+	}
+	l.lastReload = l.Now()
+	mapper, err := l.NewMapper()
+	if err != nil {
+		return false
+	}
+	l.mapper = mapper
+	return true
+}
+
+// KindFor implements meta.RESTMapper.
+func (l *Lazy) KindFor(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	gvk, err := l.delegate().KindFor(resource)
+	if l.reloadOnNoMatch(err) {
+		return l.delegate().KindFor(resource)
+	}
+	return gvk, err
+}
+
+// KindsFor implements meta.RESTMapper.
+func (l *Lazy) KindsFor(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+	gvks, err := l.delegate().KindsFor(resource)
+	if l.reloadOnNoMatch(err) {
+		return l.delegate().KindsFor(resource)
+	}
+	return gvks, err
+}
+
+// ResourceFor implements meta.RESTMapper.
+func (l *Lazy) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	gvr, err := l.delegate().ResourceFor(input)
+	if l.reloadOnNoMatch(err) {
+		return l.delegate().ResourceFor(input)
+	}
+	return gvr, err
+}
+
+// ResourcesFor implements meta.RESTMapper.
+func (l *Lazy) ResourcesFor(input schema.GroupVersionResource) ([]schema.GroupVersionResource, error) {
+	gvrs, err := l.delegate().ResourcesFor(input)
+	if l.reloadOnNoMatch(err) {
+		return l.delegate().ResourcesFor(input)
+	}
+	return gvrs, err
+}
+
+// RESTMapping implements meta.RESTMapper.
+func (l *Lazy) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	m, err := l.delegate().RESTMapping(gk, versions...)
+	if l.reloadOnNoMatch(err) {
+		return l.delegate().RESTMapping(gk, versions...)
+	}
+	return m, err
+}
+
+// RESTMappings implements meta.RESTMapper.
+func (l *Lazy) RESTMappings(gk schema.GroupKind, versions ...string) ([]*meta.RESTMapping, error) {
+	ms, err := l.delegate().RESTMappings(gk, versions...)
+	if l.reloadOnNoMatch(err) {
+		return l.delegate().RESTMappings(gk, versions...)
+	}
+	return ms, err
+}
+
+// ResourceSingularizer implements meta.RESTMapper.
+func (l *Lazy) ResourceSingularizer(resource string) (string, error) {
+	return l.delegate().ResourceSingularizer(resource)
+}
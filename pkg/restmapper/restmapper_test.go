@@ -0,0 +1,70 @@
+package restmapper
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var podGroupGVK = schema.GroupVersionKind{Group: "scheduling.volcano.sh", Version: "v1beta1", Kind: "PodGroup"}
+
+// fakeDiscovery returns the mappings of the installed kinds.
+type fakeDiscovery struct {
+	installed []schema.GroupVersionKind
+	loads     int
+}
+
+func (d *fakeDiscovery) newMapper() (meta.RESTMapper, error) {
+	d.loads++
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range d.installed {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+	return mapper, nil
+}
+
+func TestLazyReloadsOnNoMatch(t *testing.T) {
+	d := &fakeDiscovery{}
+	l, err := newLazy(d.newMapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Now()
+	l.Now = func() time.Time { return now }
+
+	if _, err := l.RESTMapping(podGroupGVK.GroupKind(), podGroupGVK.Version); !meta.IsNoMatchError(err) {
+		t.Fatalf("expected a no match error before the CRD is installed, got %v", err)
+	}
+
+	// The CRD is installed after the manager starts.
+	d.installed = append(d.installed, podGroupGVK)
+	if _, err := l.RESTMapping(podGroupGVK.GroupKind(), podGroupGVK.Version); !meta.IsNoMatchError(err) {
+		t.Errorf("expected the mappings not to be reloaded in the interval, got %v", err)
+	}
+	if d.loads != 1 {
+		t.Errorf("expected the mappings to be loaded once, got %d", d.loads)
+	}
+
+	now = now.Add(DefaultReloadInterval)
+	mapping, err := l.RESTMapping(podGroupGVK.GroupKind(), podGroupGVK.Version)
+	if err != nil {
+		t.Fatalf("expected the mapping after the reload, got %v", err)
+	}
+	if mapping.Resource.Resource != "podgroups" {
+		t.Errorf("expected the resource podgroups, got %v", mapping.Resource)
+	}
+	if d.loads != 2 {
+		t.Errorf("expected the mappings to be reloaded once, got %d loads", d.loads)
+	}
+
+	// The found kinds do not reload the mappings.
+	now = now.Add(DefaultReloadInterval)
+	if _, err := l.RESTMapping(podGroupGVK.GroupKind(), podGroupGVK.Version); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if d.loads != 2 {
+		t.Errorf("expected no reload for a found kind, got %d loads", d.loads)
+	}
+}
@@ -0,0 +1,116 @@
+package serve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
+)
+
+const (
+	// SyncPeriod is the period to check the status of the serve applications.
+	SyncPeriod = 10 * time.Second
+
+	applicationsPath = "/api/serve/applications/"
+
+	// ApplicationRunning is the status of a healthy serve application.
+	ApplicationRunning = "RUNNING"
+)
+
+// Interface deploys the serve config to the Ray cluster and gets the status of
+// the serve applications.
+type Interface interface {
+	// UpdateConfig deploys the serve config in JSON format to the Ray cluster.
+	UpdateConfig(ray *rayv1.Ray, config []byte) error
+	// GetApplications returns the status of the serve applications keyed by the name.
+	GetApplications(ray *rayv1.Ray) (map[string]string, error)
+}
+
+// HTTPClient talks to the dashboard of the head through the head service. The
+// dashboard port is got from the Ray.
+type HTTPClient struct {
+	Client *http.Client
+}
+
+// NewHTTPClient returns a new HTTPClient.
+func NewHTTPClient(timeout time.Duration) Interface {
+	return &HTTPClient{
+		Client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// applicationsStatus is the response of the serve applications API of the dashboard.
+type applicationsStatus struct {
+	Applications map[string]struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	} `json:"applications"`
+}
+
+// UpdateConfig deploys the serve config to the Ray cluster.
+func (c HTTPClient) UpdateConfig(ray *rayv1.Ray, config []byte) error {
+	url := c.url(ray)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(config))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update the serve config to %s: %s: %s", url, resp.Status, body)
+	}
+	return nil
+}
+
+// GetApplications gets the status of the serve applications from the Ray cluster.
+func (c HTTPClient) GetApplications(ray *rayv1.Ray) (map[string]string, error) {
+	url := c.url(ray)
+	resp, err := c.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the serve applications from %s: %s", url, resp.Status)
+	}
+
+	status := &applicationsStatus{}
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return nil, err
+	}
+	applications := map[string]string{}
+	for name, app := range status.Applications {
+		applications[name] = app.Status
+	}
+	return applications, nil
+}
+
+func (c HTTPClient) url(ray *rayv1.Ray) string {
+	return fmt.Sprintf("http://%s.%s.svc:%d%s", composer.GetHeadName(ray.Name),
+		ray.Namespace, composer.GetDashboardPort(&ray.Spec), applicationsPath)
+}
+
+// IsHealthy returns true if there is at least one application and all of them are running.
+func IsHealthy(applications map[string]string) bool {
+	if len(applications) == 0 {
+		return false
+	}
+	for _, status := range applications {
+		if status != ApplicationRunning {
+			return false
+		}
+	}
+	return true
+}
@@ -1,10 +1,25 @@
 package validator
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/yaml"
 
 	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/composer"
 	"github.com/kubeflow/ray-operator/pkg/consts"
 )
 
@@ -12,9 +27,28 @@ const (
 	ValidatorName = "ray-operator-validator"
 )
 
+var (
+	// maxRayNameLength keeps the names derived from the name of the Ray in 63 characters,
+	// the longest of which is the name of the service governing the head.
+	maxRayNameLength = validation.DNS1035LabelMaxLength - len(composer.GetHeadGoverningServiceName(""))
+	// maxRayJobNameLength keeps the name of the Ray of the RayJob in maxRayNameLength.
+	maxRayJobNameLength = maxRayNameLength - len(composer.GetRayNameForJob(""))
+	// maxSpecHash is the longest hash of the cluster spec of a RayService.
+	maxSpecHash = strconv.FormatUint(math.MaxUint64, 16)
+	// maxRayServiceNameLength keeps the names of the Rays of the RayService, which end
+	// with the hash of the cluster spec, in maxRayNameLength.
+	maxRayServiceNameLength = maxRayNameLength - len(composer.GetRayNameForService("", maxSpecHash))
+
+	rayVersionPattern = regexp.MustCompile(`^[0-9]+\.[0-9]+(\.[0-9]+)?$`)
+	// rayStartParamPattern matches the names of the parameters of `ray start`.
+	rayStartParamPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
+
 // Interface validates the Ray specification.
 type Interface interface {
 	ValidateRay(ray *rayv1.Ray) error
+	ValidateRayJob(job *rayv1.RayJob) error
+	ValidateRayService(svc *rayv1.RayService) error
 }
 
 // Validator is the default implementation for the Interface.
@@ -31,9 +65,476 @@ func New(recorder record.EventRecorder, log logr.Logger) Interface {
 	}
 }
 
-// ValidateRay validates a Ray specification.
+// ValidateRay validates a Ray specification. The returned error is an
+// aggregate of field.Error, one for every violated rule.
 func (v Validator) ValidateRay(ray *rayv1.Ray) error {
-	v.Event(ray, consts.EventWarning, consts.ReasonValidationFailed, "Not Implemented")
-	v.Log.V(1).Info("Validation is not implemented, return nil")
-	return nil
+	errs := validateName(ray.Name, maxRayNameLength)
+	errs = append(errs, validateRay(ray, field.NewPath("spec"))...)
+	// The autoscaling of the v2 worker groups which v1 cannot represent is kept in the
+	// annotation by the conversion, and it is not supported by the controller.
+	if _, ok := ray.Annotations[rayv1.AnnotationWorkerGroupAutoscaling]; ok {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "workerGroups"),
+			"autoscaling is only supported for the only worker group named default"))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	err := errs.ToAggregate()
+	v.Log.V(1).Info("Invalid Ray specification", "namespace", ray.Namespace,
+		"name", ray.Name, "error", err.Error())
+	v.Event(ray, consts.EventWarning, consts.ReasonValidationFailed, err.Error())
+	return err
+}
+
+// ValidateRayJob validates a RayJob specification. The returned error is an
+// aggregate of field.Error, one for every violated rule.
+func (v Validator) ValidateRayJob(job *rayv1.RayJob) error {
+	path := field.NewPath("spec")
+	errs := field.ErrorList{}
+	errs = append(errs, validateName(job.Name, maxRayJobNameLength)...)
+	if job.Spec.Entrypoint == "" {
+		errs = append(errs, field.Required(path.Child("entrypoint"), "entrypoint must be specified"))
+	}
+	if job.Spec.RuntimeEnv != "" && !json.Valid([]byte(job.Spec.RuntimeEnv)) {
+		errs = append(errs, field.Invalid(path.Child("runtimeEnv"), job.Spec.RuntimeEnv,
+			"must be in JSON format"))
+	}
+	if job.Spec.TTLSecondsAfterFinished != nil && *job.Spec.TTLSecondsAfterFinished < 0 {
+		errs = append(errs, field.Invalid(path.Child("ttlSecondsAfterFinished"),
+			*job.Spec.TTLSecondsAfterFinished, "must be greater than or equal to 0"))
+	}
+	errs = append(errs, validateDashboardVersion(job.Spec.RaySpec.RayVersion,
+		path.Child("rayClusterSpec", "rayVersion"), "for a RayJob")...)
+	// The Ray of the job is composed and defaulted by the controller, which fails it
+	// if it is invalid, thus it is validated here the same as a Ray.
+	ray := &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      composer.GetRayNameForJob(job.Name),
+			Namespace: job.Namespace,
+		},
+		Spec: *job.Spec.RaySpec.DeepCopy(),
+	}
+	ray.Default()
+	errs = append(errs, validateRay(ray, path.Child("rayClusterSpec"))...)
+	if len(errs) == 0 {
+		return nil
+	}
+	err := errs.ToAggregate()
+	v.Log.V(1).Info("Invalid RayJob specification", "namespace", job.Namespace,
+		"name", job.Name, "error", err.Error())
+	v.Event(job, consts.EventWarning, consts.ReasonValidationFailed, err.Error())
+	return err
+}
+
+// ValidateRayService validates a RayService specification. The returned error is an
+// aggregate of field.Error, one for every violated rule.
+func (v Validator) ValidateRayService(svc *rayv1.RayService) error {
+	path := field.NewPath("spec")
+	errs := field.ErrorList{}
+	if svc.Spec.ServeConfig == "" {
+		errs = append(errs, field.Required(path.Child("serveConfig"), "serveConfig must be specified"))
+	} else if _, err := yaml.YAMLToJSON([]byte(svc.Spec.ServeConfig)); err != nil {
+		errs = append(errs, field.Invalid(path.Child("serveConfig"), svc.Spec.ServeConfig,
+			fmt.Sprintf("must be in YAML or JSON format: %v", err)))
+	}
+	errs = append(errs, validateName(svc.Name, maxRayServiceNameLength)...)
+	errs = append(errs, validateDashboardVersion(svc.Spec.RaySpec.RayVersion,
+		path.Child("rayClusterSpec", "rayVersion"), "for a RayService")...)
+	// The Ray of the service is defaulted by the Ray controller, which fails it if it is
+	// invalid, thus it is validated here the same as a Ray.
+	ray := &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      composer.GetRayNameForService(svc.Name, maxSpecHash),
+			Namespace: svc.Namespace,
+		},
+		Spec: *svc.Spec.RaySpec.DeepCopy(),
+	}
+	ray.Default()
+	errs = append(errs, validateRay(ray, path.Child("rayClusterSpec"))...)
+	if len(errs) == 0 {
+		return nil
+	}
+	err := errs.ToAggregate()
+	v.Log.V(1).Info("Invalid RayService specification", "namespace", svc.Namespace,
+		"name", svc.Name, "error", err.Error())
+	v.Event(svc, consts.EventWarning, consts.ReasonValidationFailed, err.Error())
+	return err
+}
+
+// validateName checks that the name of the object is not longer than maxLength, thus
+// the names of the objects derived from it are valid.
+func validateName(name string, maxLength int) field.ErrorList {
+	errs := field.ErrorList{}
+	if len(name) > maxLength {
+		errs = append(errs, field.TooLong(field.NewPath("metadata", "name"), name, maxLength))
+	}
+	return errs
+}
+
+// validateRay validates the specification of the Ray and the names of the worker
+// groups, which are a part of the values of the worker labels. The path is the path
+// of the specification.
+func validateRay(ray *rayv1.Ray, path *field.Path) field.ErrorList {
+	errs := validateRaySpec(&ray.Spec, path)
+	maxLength := validation.LabelValueMaxLength - len(composer.GetWorkerName(ray.Name, ""))
+	for i, group := range ray.Spec.WorkerGroups {
+		if len(group.Name) > maxLength {
+			errs = append(errs, field.TooLong(path.Child("workerGroups").Index(i).Child("name"),
+				group.Name, maxLength))
+		}
+	}
+	return errs
+}
+
+func validateRaySpec(spec *rayv1.RaySpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if spec.RayVersion != "" && !rayVersionPattern.MatchString(spec.RayVersion) {
+		errs = append(errs, field.Invalid(path.Child("rayVersion"), spec.RayVersion,
+			"must be in the form of major.minor[.patch]"))
+	}
+	errs = append(errs, validateRayStartParams(spec.RayStartParams, path.Child("rayStartParams"))...)
+	errs = append(errs, validateHead(spec.Head, path.Child("head"))...)
+	if spec.Head != nil && spec.Head.Ingress != nil {
+		errs = append(errs, validateDashboardVersion(spec.RayVersion, path.Child("rayVersion"),
+			"when head.ingress is set")...)
+	}
+	if spec.Autoscaling != nil {
+		errs = append(errs, validateAutoscaling(spec, path.Child("autoscaling"))...)
+		errs = append(errs, validateDashboardVersion(spec.RayVersion, path.Child("rayVersion"),
+			"when autoscaling is set")...)
+	}
+	if spec.Termination != nil {
+		errs = append(errs, validateTermination(spec.Termination, path.Child("termination"))...)
+	}
+	if spec.Scheduling != nil {
+		errs = append(errs, validateScheduling(spec.Scheduling, path.Child("scheduling"))...)
+	}
+	if spec.HeadFaultTolerance != nil {
+		errs = append(errs, validateHeadFaultTolerance(spec, path)...)
+	}
+	if len(spec.WorkerGroups) == 0 {
+		errs = append(errs, validateWorker(&spec.Worker, path.Child("worker"))...)
+		return errs
+	}
+	if spec.Worker.Replicas != nil || spec.Worker.Template != nil {
+		errs = append(errs, field.Forbidden(path.Child("worker"),
+			"worker must not be set together with workerGroups, e.g. by the scale subresource "+
+				"which only scales spec.worker"))
+	}
+	errs = append(errs, validateWorkerGroups(spec.WorkerGroups, path.Child("workerGroups"))...)
+	return errs
+}
+
+// validateDashboardVersion checks that the head of the Ray version serves the dashboard,
+// which is required by the features talking to it.
+func validateDashboardVersion(version string, path *field.Path, feature string) field.ErrorList {
+	errs := field.ErrorList{}
+	if !composer.SupportsDashboard(version) {
+		errs = append(errs, field.Invalid(path, version, "must be 1.11 or later "+feature))
+	}
+	return errs
+}
+
+// validateRayStartParams checks that the parameters are not set by the operator and
+// that the ports are valid.
+func validateRayStartParams(params map[string]string, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pPath := path.Key(name)
+		if !rayStartParamPattern.MatchString(name) {
+			errs = append(errs, field.Invalid(pPath, name,
+				"must be a parameter of ray start without the leading dashes"))
+			continue
+		}
+		if composer.IsManagedRayStartParam(name) {
+			errs = append(errs, field.Forbidden(pPath, "is set by the operator"))
+			continue
+		}
+		if name == "port" || strings.HasSuffix(name, "-port") {
+			port, err := strconv.Atoi(params[name])
+			if err != nil || len(validation.IsValidPortNum(port)) != 0 {
+				errs = append(errs, field.Invalid(pPath, params[name],
+					"must be a valid port number"))
+			}
+		}
+	}
+	return errs
+}
+
+func validateAutoscaling(spec *rayv1.RaySpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	autoscaling := spec.Autoscaling
+	if len(spec.WorkerGroups) != 0 {
+		errs = append(errs, field.Forbidden(path,
+			"autoscaling must not be set together with workerGroups"))
+	}
+	minReplicas := int32(0)
+	if autoscaling.MinReplicas != nil {
+		minReplicas = *autoscaling.MinReplicas
+		if minReplicas < 0 {
+			errs = append(errs, field.Invalid(path.Child("minReplicas"), minReplicas,
+				"must be greater than or equal to 0"))
+		}
+	}
+	if autoscaling.MaxReplicas <= 0 || autoscaling.MaxReplicas < minReplicas {
+		errs = append(errs, field.Invalid(path.Child("maxReplicas"), autoscaling.MaxReplicas,
+			"must be greater than 0 and minReplicas"))
+	}
+	if autoscaling.UpscalingSpeed != nil && *autoscaling.UpscalingSpeed <= 0 {
+		errs = append(errs, field.Invalid(path.Child("upscalingSpeed"), *autoscaling.UpscalingSpeed,
+			"must be greater than 0"))
+	}
+	if autoscaling.IdleTimeoutSeconds != nil && *autoscaling.IdleTimeoutSeconds < 0 {
+		errs = append(errs, field.Invalid(path.Child("idleTimeoutSeconds"), *autoscaling.IdleTimeoutSeconds,
+			"must be greater than or equal to 0"))
+	}
+	return errs
+}
+
+func validateTermination(termination *rayv1.TerminationSpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if termination.GracePeriodSeconds != nil && *termination.GracePeriodSeconds < 0 {
+		errs = append(errs, field.Invalid(path.Child("gracePeriodSeconds"), *termination.GracePeriodSeconds,
+			"must be greater than or equal to 0"))
+	}
+	if hook := termination.PreDeleteHook; hook != nil {
+		hPath := path.Child("preDeleteHook", "template")
+		if len(hook.Template.Spec.Containers) == 0 {
+			errs = append(errs, field.Required(hPath.Child("spec", "containers"),
+				"must contain at least one container"))
+		}
+		errs = append(errs, validateTemplate(&hook.Template, hPath)...)
+	}
+	return errs
+}
+
+func validateScheduling(scheduling *rayv1.SchedulingSpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	switch scheduling.Provider {
+	case rayv1.SchedulingVolcano, rayv1.SchedulingCoscheduling:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("provider"), scheduling.Provider,
+			[]string{string(rayv1.SchedulingVolcano), string(rayv1.SchedulingCoscheduling)}))
+	}
+	if scheduling.MinWorkers != nil && *scheduling.MinWorkers < 0 {
+		errs = append(errs, field.Invalid(path.Child("minWorkers"), *scheduling.MinWorkers,
+			"must be greater than or equal to 0"))
+	}
+	if scheduling.ScheduleTimeoutSeconds != nil && *scheduling.ScheduleTimeoutSeconds <= 0 {
+		errs = append(errs, field.Invalid(path.Child("scheduleTimeoutSeconds"), *scheduling.ScheduleTimeoutSeconds,
+			"must be greater than 0"))
+	}
+	return errs
+}
+
+// validateHeadFaultTolerance checks that the Ray version supports the GCS fault
+// tolerance and that the external Redis is specified.
+func validateHeadFaultTolerance(spec *rayv1.RaySpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	ft := spec.HeadFaultTolerance
+	ftPath := path.Child("headFaultTolerance")
+	if !composer.SupportsGCSFaultTolerance(spec.RayVersion) {
+		errs = append(errs, field.Invalid(path.Child("rayVersion"), spec.RayVersion,
+			"must be 2.0 or later when headFaultTolerance is set"))
+	}
+	if ft.RedisAddress == "" {
+		errs = append(errs, field.Required(ftPath.Child("redisAddress"), "redisAddress must be specified"))
+	} else if host, port, err := net.SplitHostPort(ft.RedisAddress); err != nil || host == "" {
+		errs = append(errs, field.Invalid(ftPath.Child("redisAddress"), ft.RedisAddress,
+			"must be in the form of host:port"))
+	} else if n, err := strconv.Atoi(port); err != nil || len(validation.IsValidPortNum(n)) != 0 {
+		errs = append(errs, field.Invalid(ftPath.Child("redisAddress"), ft.RedisAddress,
+			"must have a valid port number"))
+	}
+	if secret := ft.RedisPasswordSecret; secret != nil {
+		sPath := ftPath.Child("redisPasswordSecret")
+		if secret.Name == "" {
+			errs = append(errs, field.Required(sPath.Child("name"), "name must be specified"))
+		}
+		if secret.Key == "" {
+			errs = append(errs, field.Required(sPath.Child("key"), "key must be specified"))
+		}
+	}
+	if ft.WorkerReconnectTimeoutSeconds != nil && *ft.WorkerReconnectTimeoutSeconds <= 0 {
+		errs = append(errs, field.Invalid(ftPath.Child("workerReconnectTimeoutSeconds"),
+			*ft.WorkerReconnectTimeoutSeconds, "must be greater than 0"))
+	}
+	if _, ok := spec.RayStartParams[composer.FaultToleranceParam]; ok {
+		errs = append(errs, field.Forbidden(path.Child("rayStartParams").Key(composer.FaultToleranceParam),
+			"is set by the operator when headFaultTolerance is set"))
+	}
+	return errs
+}
+
+func validateWorkerGroups(groups []rayv1.WorkerGroupSpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	names := map[string]bool{}
+	for i := range groups {
+		gPath := path.Index(i)
+		name := groups[i].Name
+		if name == "" {
+			errs = append(errs, field.Required(gPath.Child("name"), "name must be specified"))
+		} else {
+			for _, msg := range validation.IsDNS1123Label(name) {
+				errs = append(errs, field.Invalid(gPath.Child("name"), name, msg))
+			}
+			if names[name] {
+				errs = append(errs, field.Duplicate(gPath.Child("name"), name))
+			}
+			if name == rayv1.DefaultWorkerGroupName {
+				errs = append(errs, field.Invalid(gPath.Child("name"), name,
+					"is reserved for the workers described by spec.worker"))
+			}
+			names[name] = true
+		}
+		errs = append(errs, validateWorker(&groups[i].ReplicaSpec, gPath)...)
+	}
+	return errs
+}
+
+func validateHead(head *rayv1.HeadSpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if head == nil {
+		return append(errs, field.Required(path, "head must be specified"))
+	}
+	// A Ray has exactly one head, which runs the GCS.
+	if head.Replicas != nil && *head.Replicas != 1 {
+		errs = append(errs, field.Invalid(path.Child("replicas"), *head.Replicas, "must be 1"))
+	}
+	switch head.WorkloadKind {
+	case "", rayv1.HeadWorkloadDeployment, rayv1.HeadWorkloadStatefulSet, rayv1.HeadWorkloadPod:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("workloadKind"), head.WorkloadKind,
+			[]string{string(rayv1.HeadWorkloadDeployment), string(rayv1.HeadWorkloadPod),
+				string(rayv1.HeadWorkloadStatefulSet)}))
+	}
+	if head.Template == nil {
+		return append(errs, field.Required(path.Child("template"), "head template must be specified"))
+	}
+	if !hasContainer(head.Template, consts.ContainerRayHead) {
+		errs = append(errs, field.Required(path.Child("template", "spec", "containers"),
+			fmt.Sprintf("must contain a container named %s", consts.ContainerRayHead)))
+	}
+	errs = append(errs, validateTemplate(head.Template, path.Child("template"))...)
+	if head.Probes != nil {
+		errs = append(errs, validateProbes(head.Probes, path.Child("probes"))...)
+	}
+	switch head.ServiceType {
+	case "", corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("serviceType"), head.ServiceType,
+			[]string{string(corev1.ServiceTypeClusterIP), string(corev1.ServiceTypeNodePort),
+				string(corev1.ServiceTypeLoadBalancer)}))
+	}
+	if head.Ingress != nil {
+		errs = append(errs, validateHeadIngress(head.Ingress, path.Child("ingress"))...)
+	}
+	return errs
+}
+
+func validateHeadIngress(ingress *rayv1.HeadIngressSpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if ingress.DashboardHost == "" && ingress.ClientHost == "" {
+		return append(errs, field.Required(path,
+			"at least one of dashboardHost and clientHost must be specified"))
+	}
+	if ingress.DashboardHost != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(ingress.DashboardHost) {
+			errs = append(errs, field.Invalid(path.Child("dashboardHost"), ingress.DashboardHost, msg))
+		}
+	}
+	if ingress.ClientHost != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(ingress.ClientHost) {
+			errs = append(errs, field.Invalid(path.Child("clientHost"), ingress.ClientHost, msg))
+		}
+	}
+	if ingress.DashboardHost != "" && ingress.DashboardHost == ingress.ClientHost {
+		errs = append(errs, field.Invalid(path.Child("clientHost"), ingress.ClientHost,
+			"must be different from dashboardHost"))
+	}
+	return errs
+}
+
+func validateWorker(worker *rayv1.ReplicaSpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if worker.Replicas != nil && *worker.Replicas <= 0 {
+		errs = append(errs, field.Invalid(path.Child("replicas"), *worker.Replicas,
+			"must be greater than 0"))
+	}
+	if worker.Template == nil {
+		return append(errs, field.Required(path.Child("template"), "worker template must be specified"))
+	}
+	errs = append(errs, validateTemplate(worker.Template, path.Child("template"))...)
+	if worker.Probes != nil {
+		errs = append(errs, validateProbes(worker.Probes, path.Child("probes"))...)
+	}
+	return errs
+}
+
+func validateProbes(probes *rayv1.ProbeSpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if probes.InitialDelaySeconds != nil && *probes.InitialDelaySeconds < 0 {
+		errs = append(errs, field.Invalid(path.Child("initialDelaySeconds"), *probes.InitialDelaySeconds,
+			"must be greater than or equal to 0"))
+	}
+	if probes.TimeoutSeconds != nil && *probes.TimeoutSeconds <= 0 {
+		errs = append(errs, field.Invalid(path.Child("timeoutSeconds"), *probes.TimeoutSeconds,
+			"must be greater than 0"))
+	}
+	if probes.PeriodSeconds != nil && *probes.PeriodSeconds <= 0 {
+		errs = append(errs, field.Invalid(path.Child("periodSeconds"), *probes.PeriodSeconds,
+			"must be greater than 0"))
+	}
+	if probes.FailureThreshold != nil && *probes.FailureThreshold <= 0 {
+		errs = append(errs, field.Invalid(path.Child("failureThreshold"), *probes.FailureThreshold,
+			"must be greater than 0"))
+	}
+	return errs
+}
+
+// validateTemplate checks that every container has an image and that the
+// ports in the pod neither share a name nor clash on the same port and protocol.
+func validateTemplate(template *corev1.PodTemplateSpec, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	names := map[string]bool{}
+	ports := map[string]bool{}
+	for i, c := range template.Spec.Containers {
+		cPath := path.Child("spec", "containers").Index(i)
+		if c.Image == "" {
+			errs = append(errs, field.Required(cPath.Child("image"), "image must be specified"))
+		}
+		for j, p := range c.Ports {
+			pPath := cPath.Child("ports").Index(j)
+			if p.Name != "" {
+				if names[p.Name] {
+					errs = append(errs, field.Duplicate(pPath.Child("name"), p.Name))
+				}
+				names[p.Name] = true
+			}
+			protocol := p.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			key := fmt.Sprintf("%d/%s", p.ContainerPort, protocol)
+			if ports[key] {
+				errs = append(errs, field.Duplicate(pPath.Child("containerPort"), key))
+			}
+			ports[key] = true
+		}
+	}
+	return errs
+}
+
+func hasContainer(template *corev1.PodTemplateSpec, name string) bool {
+	for _, c := range template.Spec.Containers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,483 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	"github.com/kubeflow/ray-operator/pkg/consts"
+)
+
+func newTestValidator() *Validator {
+	return &Validator{
+		EventRecorder: record.NewFakeRecorder(10),
+		Log:           logf.NullLogger{},
+	}
+}
+
+// newTestRay returns a valid Ray defaulted by the mutating webhook.
+func newTestRay() *rayv1.Ray {
+	ray := &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+	}
+	ray.Default()
+	return ray
+}
+
+func int32Ptr(n int32) *int32 {
+	return &n
+}
+
+func newTestHeadFaultTolerance() *rayv1.HeadFaultToleranceSpec {
+	return &rayv1.HeadFaultToleranceSpec{
+		RedisAddress: "redis.default.svc:6379",
+		RedisPasswordSecret: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "redis"},
+			Key:                  "password",
+		},
+	}
+}
+
+func TestValidateRay(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(ray *rayv1.Ray)
+		// errs are the field paths of the expected errors, the Ray is valid if it is empty.
+		errs []string
+	}{
+		{
+			name:   "defaulted",
+			mutate: func(ray *rayv1.Ray) {},
+		},
+		{
+			name: "zero worker replicas",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Worker.Replicas = int32Ptr(0)
+			},
+			errs: []string{"spec.worker.replicas"},
+		},
+		{
+			name: "negative worker replicas",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Worker.Replicas = int32Ptr(-1)
+			},
+			errs: []string{"spec.worker.replicas"},
+		},
+		{
+			name: "zero head replicas",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Head.Replicas = int32Ptr(0)
+			},
+			errs: []string{"spec.head.replicas"},
+		},
+		{
+			name: "two head replicas",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Head.Replicas = int32Ptr(2)
+			},
+			errs: []string{"spec.head.replicas"},
+		},
+		{
+			name: "two head replicas in a StatefulSet",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Head.WorkloadKind = rayv1.HeadWorkloadStatefulSet
+				ray.Spec.Head.Replicas = int32Ptr(2)
+			},
+			errs: []string{"spec.head.replicas"},
+		},
+		{
+			name: "unknown workload kind",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Head.WorkloadKind = "Job"
+			},
+			errs: []string{"spec.head.workloadKind"},
+		},
+		{
+			name: "head without the ray-head container",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Head.Template.Spec.Containers[0].Name = "main"
+			},
+			errs: []string{"spec.head.template.spec.containers"},
+		},
+		{
+			name: "missing image",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Worker.Template.Spec.Containers[0].Image = ""
+			},
+			errs: []string{"spec.worker.template.spec.containers[0].image"},
+		},
+		{
+			name: "duplicate port name",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Head.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{
+					{Name: "gcs", ContainerPort: 6379},
+					{Name: "gcs", ContainerPort: 6380},
+				}
+			},
+			errs: []string{"spec.head.template.spec.containers[0].ports[1].name"},
+		},
+		{
+			name: "clashing ports",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Head.Template.Spec.Containers = append(ray.Spec.Head.Template.Spec.Containers,
+					corev1.Container{
+						Name:  "sidecar",
+						Image: "busybox",
+						Ports: []corev1.ContainerPort{{ContainerPort: 8265}},
+					})
+				ray.Spec.Head.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{
+					{ContainerPort: 8265, Protocol: corev1.ProtocolTCP},
+				}
+			},
+			errs: []string{"spec.head.template.spec.containers[1].ports[0].containerPort"},
+		},
+		{
+			name: "same port on different protocols",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Head.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{
+					{ContainerPort: 8265, Protocol: corev1.ProtocolTCP},
+					{ContainerPort: 8265, Protocol: corev1.ProtocolUDP},
+				}
+			},
+		},
+		{
+			name: "worker together with worker groups",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.WorkerGroups = []rayv1.WorkerGroupSpec{
+					{Name: "cpu", ReplicaSpec: ray.Spec.Worker},
+				}
+			},
+			errs: []string{"spec.worker"},
+		},
+		{
+			name: "duplicate worker groups",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.WorkerGroups = []rayv1.WorkerGroupSpec{
+					{Name: "cpu", ReplicaSpec: ray.Spec.Worker},
+					{Name: "cpu", ReplicaSpec: ray.Spec.Worker},
+				}
+				ray.Spec.Worker = rayv1.ReplicaSpec{}
+			},
+			errs: []string{"spec.workerGroups[1].name"},
+		},
+		{
+			name: "worker group named default",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.WorkerGroups = []rayv1.WorkerGroupSpec{
+					{Name: rayv1.DefaultWorkerGroupName, ReplicaSpec: ray.Spec.Worker},
+				}
+				ray.Spec.Worker = rayv1.ReplicaSpec{}
+			},
+			errs: []string{"spec.workerGroups[0].name"},
+		},
+		{
+			name: "autoscaling of a worker group converted from v2",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.WorkerGroups = []rayv1.WorkerGroupSpec{
+					{Name: "cpu", ReplicaSpec: ray.Spec.Worker},
+				}
+				ray.Spec.Worker = rayv1.ReplicaSpec{}
+				ray.Annotations = map[string]string{
+					rayv1.AnnotationWorkerGroupAutoscaling: `{"cpu":{"maxReplicas":5}}`,
+				}
+			},
+			errs: []string{"spec.workerGroups"},
+		},
+		{
+			name: "long worker group name",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.WorkerGroups = []rayv1.WorkerGroupSpec{
+					{Name: strings.Repeat("a", 60), ReplicaSpec: ray.Spec.Worker},
+				}
+				ray.Spec.Worker = rayv1.ReplicaSpec{}
+			},
+			errs: []string{"spec.workerGroups[0].name"},
+		},
+		{
+			name: "long name",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Name = strings.Repeat("a", 50)
+			},
+			errs: []string{"metadata.name"},
+		},
+		{
+			name: "autoscaling",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "2.9.0"
+				ray.Spec.Autoscaling = &rayv1.AutoscalingSpec{MaxReplicas: 10}
+			},
+		},
+		{
+			name: "autoscaling without the dashboard",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Autoscaling = &rayv1.AutoscalingSpec{MaxReplicas: 10}
+			},
+			errs: []string{"spec.rayVersion"},
+		},
+		{
+			name: "ingress",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "1.11"
+				ray.Spec.Head.Ingress = &rayv1.HeadIngressSpec{DashboardHost: "ray.example.com"}
+			},
+		},
+		{
+			name: "ingress without the dashboard",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "1.10.0"
+				ray.Spec.Head.Ingress = &rayv1.HeadIngressSpec{DashboardHost: "ray.example.com"}
+			},
+			errs: []string{"spec.rayVersion"},
+		},
+		{
+			name: "head fault tolerance",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "2.9.0"
+				ray.Spec.HeadFaultTolerance = newTestHeadFaultTolerance()
+			},
+		},
+		{
+			name: "head fault tolerance before Ray 2.0",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "1.13.0"
+				ray.Spec.HeadFaultTolerance = newTestHeadFaultTolerance()
+			},
+			errs: []string{"spec.rayVersion"},
+		},
+		{
+			name: "head fault tolerance without the redis address",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "2.9.0"
+				ray.Spec.HeadFaultTolerance = newTestHeadFaultTolerance()
+				ray.Spec.HeadFaultTolerance.RedisAddress = ""
+			},
+			errs: []string{"spec.headFaultTolerance.redisAddress"},
+		},
+		{
+			name: "head fault tolerance with the redis address without the port",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "2.9.0"
+				ray.Spec.HeadFaultTolerance = newTestHeadFaultTolerance()
+				ray.Spec.HeadFaultTolerance.RedisAddress = "redis"
+			},
+			errs: []string{"spec.headFaultTolerance.redisAddress"},
+		},
+		{
+			name: "head fault tolerance with an invalid redis port",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "2.9.0"
+				ray.Spec.HeadFaultTolerance = newTestHeadFaultTolerance()
+				ray.Spec.HeadFaultTolerance.RedisAddress = "redis:70000"
+			},
+			errs: []string{"spec.headFaultTolerance.redisAddress"},
+		},
+		{
+			name: "head fault tolerance with an incomplete password secret",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "2.9.0"
+				ray.Spec.HeadFaultTolerance = newTestHeadFaultTolerance()
+				ray.Spec.HeadFaultTolerance.RedisPasswordSecret.Key = ""
+			},
+			errs: []string{"spec.headFaultTolerance.redisPasswordSecret.key"},
+		},
+		{
+			name: "head fault tolerance with a zero reconnect timeout",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "2.9.0"
+				ray.Spec.HeadFaultTolerance = newTestHeadFaultTolerance()
+				ray.Spec.HeadFaultTolerance.WorkerReconnectTimeoutSeconds = int32Ptr(0)
+			},
+			errs: []string{"spec.headFaultTolerance.workerReconnectTimeoutSeconds"},
+		},
+		{
+			name: "head fault tolerance with the redis password param",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.RayVersion = "2.9.0"
+				ray.Spec.HeadFaultTolerance = newTestHeadFaultTolerance()
+				ray.Spec.RayStartParams = map[string]string{"redis-password": "secret"}
+			},
+			errs: []string{"spec.rayStartParams[redis-password]"},
+		},
+		{
+			name: "multiple errors",
+			mutate: func(ray *rayv1.Ray) {
+				ray.Spec.Head.Replicas = int32Ptr(3)
+				ray.Spec.Worker.Replicas = int32Ptr(0)
+			},
+			errs: []string{"spec.head.replicas", "spec.worker.replicas"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ray := newTestRay()
+			c.mutate(ray)
+			err := newTestValidator().ValidateRay(ray)
+			if len(c.errs) == 0 {
+				if err != nil {
+					t.Fatalf("expected the Ray to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors on %v, got nil", c.errs)
+			}
+			for _, path := range c.errs {
+				if !strings.Contains(err.Error(), path+":") {
+					t.Errorf("expected an error on %s, got %v", path, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRayRecordsEvent(t *testing.T) {
+	v := newTestValidator()
+	ray := newTestRay()
+	ray.Spec.Head.Replicas = int32Ptr(2)
+	if err := v.ValidateRay(ray); err == nil {
+		t.Fatal("expected the Ray to be invalid")
+	}
+	recorder := v.EventRecorder.(*record.FakeRecorder)
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, consts.ReasonValidationFailed) {
+			t.Errorf("expected a %s event, got %q", consts.ReasonValidationFailed, event)
+		}
+	default:
+		t.Error("expected an event to be recorded")
+	}
+}
+
+func TestValidateDashboardVersion(t *testing.T) {
+	cases := []struct {
+		version string
+		valid   bool
+	}{
+		{"", false},
+		{"1.10.0", false},
+		{"1.11", true},
+		{"2.9.0", true},
+	}
+	for _, c := range cases {
+		t.Run(c.version, func(t *testing.T) {
+			job := &rayv1.RayJob{Spec: rayv1.RayJobSpec{
+				Entrypoint: "python main.py",
+				RaySpec:    rayv1.RaySpec{RayVersion: c.version},
+			}}
+			if err := newTestValidator().ValidateRayJob(job); (err == nil) != c.valid {
+				t.Errorf("expected the RayJob valid %v, got %v", c.valid, err)
+			}
+			svc := &rayv1.RayService{Spec: rayv1.RayServiceSpec{
+				ServeConfig: "applications: []",
+				RaySpec:     rayv1.RaySpec{RayVersion: c.version},
+			}}
+			if err := newTestValidator().ValidateRayService(svc); (err == nil) != c.valid {
+				t.Errorf("expected the RayService valid %v, got %v", c.valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateRayJobRaySpec(t *testing.T) {
+	job := &rayv1.RayJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint: "python main.py",
+			RaySpec: rayv1.RaySpec{
+				RayVersion: "2.9.0",
+			},
+		},
+	}
+	if err := newTestValidator().ValidateRayJob(job); err != nil {
+		t.Fatalf("expected the RayJob to be valid, got %v", err)
+	}
+
+	job.Spec.RaySpec.Worker.Replicas = int32Ptr(0)
+	err := newTestValidator().ValidateRayJob(job)
+	if err == nil || !strings.Contains(err.Error(), "spec.rayClusterSpec.worker.replicas:") {
+		t.Errorf("expected an error on spec.rayClusterSpec.worker.replicas, got %v", err)
+	}
+
+	job.Spec.RaySpec.Worker.Replicas = nil
+	job.Name = strings.Repeat("a", maxRayJobNameLength+1)
+	err = newTestValidator().ValidateRayJob(job)
+	if err == nil || !strings.Contains(err.Error(), "metadata.name:") {
+		t.Errorf("expected an error on metadata.name, got %v", err)
+	}
+}
+
+func TestValidateRayService(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(svc *rayv1.RayService)
+		// errs are the field paths of the expected errors, the RayService is valid if it is empty.
+		errs []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(svc *rayv1.RayService) {},
+		},
+		{
+			name: "missing serve config",
+			mutate: func(svc *rayv1.RayService) {
+				svc.Spec.ServeConfig = ""
+			},
+			errs: []string{"spec.serveConfig"},
+		},
+		{
+			name: "invalid ray spec",
+			mutate: func(svc *rayv1.RayService) {
+				svc.Spec.RaySpec.Worker.Replicas = int32Ptr(0)
+			},
+			errs: []string{"spec.rayClusterSpec.worker.replicas"},
+		},
+		{
+			name: "longest name",
+			mutate: func(svc *rayv1.RayService) {
+				svc.Name = strings.Repeat("a", maxRayServiceNameLength)
+			},
+		},
+		{
+			name: "long name",
+			mutate: func(svc *rayv1.RayService) {
+				svc.Name = strings.Repeat("a", maxRayServiceNameLength+1)
+			},
+			errs: []string{"metadata.name"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := &rayv1.RayService{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+				Spec: rayv1.RayServiceSpec{
+					ServeConfig: "applications: []",
+					RaySpec:     rayv1.RaySpec{RayVersion: "2.9.0"},
+				},
+			}
+			c.mutate(svc)
+			err := newTestValidator().ValidateRayService(svc)
+			if len(c.errs) == 0 {
+				if err != nil {
+					t.Fatalf("expected the RayService to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors on %v, got nil", c.errs)
+			}
+			for _, path := range c.errs {
+				if !strings.Contains(err.Error(), path+":") {
+					t.Errorf("expected an error on %s, got %v", path, err)
+				}
+			}
+		})
+	}
+}
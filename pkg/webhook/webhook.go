@@ -1,14 +1,191 @@
 package webhook
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/api/admission/v1beta1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	rayv2 "github.com/kubeflow/ray-operator/api/v2"
+	"github.com/kubeflow/ray-operator/pkg/validator"
 )
 
+const (
+	// MutatingV2Path is the path the mutating webhook for Ray v2 is served at. It follows
+	// the same convention as the path generated for the mutating webhook of v1.
+	MutatingV2Path = "/mutate-ray-kubeflow-org-v2-ray"
+	// ValidatingPath is the path the validating webhook for Ray is served at. It follows
+	// the same convention as the path generated for the mutating webhook.
+	ValidatingPath = "/validate-ray-kubeflow-org-v1-ray"
+)
+
+// +kubebuilder:webhook:path=/mutate-ray-kubeflow-org-v1-ray,mutating=true,failurePolicy=fail,groups=ray.kubeflow.org,resources=rays,verbs=create;update,versions=v1,name=mutating.ray.kubeflow.org
+// +kubebuilder:webhook:path=/mutate-ray-kubeflow-org-v2-ray,mutating=true,failurePolicy=fail,groups=ray.kubeflow.org,resources=rays,verbs=create;update,versions=v2,name=mutating.v2.ray.kubeflow.org
+
+// Mutating sets the default specification for Ray by Ray.Default(). The builder
+// also serves the conversion webhook for Ray at /convert, since the versions of
+// Ray implement conversion.Hub and conversion.Convertible. A v2 Ray is converted
+// to v1 to be defaulted, and the patch is computed against the v2 request.
 type Mutating struct {
+	decoder *admission.Decoder
 }
 
+var _ admission.DecoderInjector = &Mutating{}
+
 // SetupWithManager setups the manager.
 func (m *Mutating) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(MutatingV2Path, &webhook.Admission{Handler: m})
 	return ctrl.NewWebhookManagedBy(mgr).
-		For(&rayv1.Ray{}).Name("mutating.experiment.kubeflow.org")
+		For(&rayv1.Ray{}).
+		Complete()
+}
+
+// InjectDecoder injects the decoder.
+func (m *Mutating) InjectDecoder(d *admission.Decoder) error {
+	m.decoder = d
+	return nil
+}
+
+// Handle handles the admission requests of Ray v2.
+func (m *Mutating) Handle(ctx context.Context, req admission.Request) admission.Response {
+	hub := &rayv2.Ray{}
+	if err := m.decoder.Decode(req, hub); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	ray := &rayv1.Ray{}
+	if err := ray.ConvertFrom(hub.DeepCopy()); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	ray.Default()
+
+	defaulted := &rayv2.Ray{TypeMeta: hub.TypeMeta}
+	if err := ray.ConvertTo(defaulted); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	marshaled, err := json.Marshal(defaulted)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// +kubebuilder:webhook:path=/validate-ray-kubeflow-org-v1-ray,mutating=false,failurePolicy=fail,groups=ray.kubeflow.org,resources=rays;rays/scale,verbs=create;update,versions=v1;v2,name=validating.ray.kubeflow.org
+
+// Validating rejects invalid Ray specifications with the rules in the validator, and
+// the scale of a Ray which the scale subresource does not support.
+type Validating struct {
+	Validator validator.Interface
+	// Client gets the Ray whose scale subresource is updated.
+	Client client.Reader
+
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &Validating{}
+
+// SetupWithManager setups the manager.
+func (v *Validating) SetupWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(ValidatingPath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+// InjectDecoder injects the decoder.
+func (v *Validating) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle handles admission requests. A v2 Ray is converted to v1 to be validated.
+func (v *Validating) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.SubResource == "scale" {
+		return v.handleScale(ctx, req)
+	}
+	ray := &rayv1.Ray{}
+	var err error
+	switch req.Operation {
+	case v1beta1.Create:
+		if err := v.decodeRay(req.Kind.Version, req.Object, ray); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = v.ValidateCreate(ray)
+	case v1beta1.Update:
+		if err := v.decodeRay(req.Kind.Version, req.Object, ray); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		old := &rayv1.Ray{}
+		if err := v.decodeRay(req.Kind.Version, req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = v.ValidateUpdate(old, ray)
+	case v1beta1.Delete:
+		err = v.ValidateDelete(ray)
+	}
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// handleScale handles the admission requests of the scale subresource, which maps to
+// spec.worker.replicas. The scale of a Ray with worker groups is rejected, since
+// spec.worker must not be set together with spec.workerGroups, otherwise the Ray fails
+// in the controller. The scaled Ray is validated as well, e.g. it must have workers.
+func (v *Validating) handleScale(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != v1beta1.Update {
+		return admission.Allowed("")
+	}
+	scale := &autoscalingv1.Scale{}
+	if err := v.decoder.DecodeRaw(req.Object, scale); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	ray := &rayv1.Ray{}
+	if err := v.Client.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: req.Name}, ray); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(ray.Spec.WorkerGroups) != 0 {
+		return admission.Denied(fmt.Sprintf("the scale subresource only scales spec.worker, "+
+			"the Ray %s has spec.workerGroups which must be scaled in the spec instead", ray.Name))
+	}
+	ray.Spec.Worker.Replicas = &scale.Spec.Replicas
+	if err := v.Validator.ValidateRay(ray); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// decodeRay decodes the raw Ray of the version into the v1 Ray.
+func (v *Validating) decodeRay(version string, raw runtime.RawExtension, ray *rayv1.Ray) error {
+	if version != rayv2.GroupVersion.Version {
+		return v.decoder.DecodeRaw(raw, ray)
+	}
+	hub := &rayv2.Ray{}
+	if err := v.decoder.DecodeRaw(raw, hub); err != nil {
+		return err
+	}
+	return ray.ConvertFrom(hub)
+}
+
+// ValidateCreate validates the Ray to be created.
+func (v *Validating) ValidateCreate(ray *rayv1.Ray) error {
+	return v.Validator.ValidateRay(ray)
+}
+
+// ValidateUpdate validates the updated Ray.
+func (v *Validating) ValidateUpdate(old, new *rayv1.Ray) error {
+	return v.Validator.ValidateRay(new)
+}
+
+// ValidateDelete validates the Ray to be deleted. Deletion is always allowed.
+func (v *Validating) ValidateDelete(ray *rayv1.Ray) error {
+	return nil
 }
@@ -0,0 +1,232 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"k8s.io/api/admission/v1beta1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	rayv1 "github.com/kubeflow/ray-operator/api/v1"
+	rayv2 "github.com/kubeflow/ray-operator/api/v2"
+	"github.com/kubeflow/ray-operator/pkg/validator"
+)
+
+func newTestValidating() *Validating {
+	return &Validating{
+		Validator: validator.New(record.NewFakeRecorder(10), logf.NullLogger{}),
+	}
+}
+
+func newTestDecoder(t *testing.T) *admission.Decoder {
+	scheme := runtime.NewScheme()
+	_ = rayv1.AddToScheme(scheme)
+	_ = rayv2.AddToScheme(scheme)
+	_ = autoscalingv1.AddToScheme(scheme)
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoder
+}
+
+// newTestRequest returns the admission request to create the Ray of the version.
+func newTestRequest(t *testing.T, version string, ray runtime.Object) admission.Request {
+	raw, err := json.Marshal(ray)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return admission.Request{AdmissionRequest: v1beta1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: rayv1.GroupVersion.Group, Version: version, Kind: "Ray"},
+		Operation: v1beta1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+}
+
+func newTestRayV2(t *testing.T, headReplicas int32) *rayv2.Ray {
+	hub := &rayv2.Ray{}
+	if err := newTestRay(headReplicas).ConvertTo(hub); err != nil {
+		t.Fatal(err)
+	}
+	hub.TypeMeta = metav1.TypeMeta{APIVersion: rayv2.GroupVersion.String(), Kind: "Ray"}
+	return hub
+}
+
+func newTestRay(headReplicas int32) *rayv1.Ray {
+	ray := &rayv1.Ray{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+	}
+	ray.Default()
+	ray.Spec.Head.Replicas = &headReplicas
+	return ray
+}
+
+func TestValidateCreate(t *testing.T) {
+	cases := []struct {
+		name    string
+		ray     *rayv1.Ray
+		allowed bool
+	}{
+		{"valid", newTestRay(1), true},
+		{"invalid", newTestRay(2), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := newTestValidating().ValidateCreate(c.ray)
+			if (err == nil) != c.allowed {
+				t.Errorf("expected allowed %v, got %v", c.allowed, err)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	cases := []struct {
+		name    string
+		old     *rayv1.Ray
+		new     *rayv1.Ray
+		allowed bool
+	}{
+		{"valid to valid", newTestRay(1), newTestRay(1), true},
+		{"valid to invalid", newTestRay(1), newTestRay(0), false},
+		// An invalid Ray, e.g. created before the webhook is enabled, could be fixed.
+		{"invalid to valid", newTestRay(0), newTestRay(1), true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := newTestValidating().ValidateUpdate(c.old, c.new)
+			if (err == nil) != c.allowed {
+				t.Errorf("expected allowed %v, got %v", c.allowed, err)
+			}
+		})
+	}
+}
+
+func TestValidateDelete(t *testing.T) {
+	if err := newTestValidating().ValidateDelete(newTestRay(2)); err != nil {
+		t.Errorf("expected the deletion to be allowed, got %v", err)
+	}
+}
+
+// newTestRayV2WithGroupAutoscaling returns a v2 Ray whose worker group other than the
+// default group is autoscaled, which v1 cannot represent.
+func newTestRayV2WithGroupAutoscaling(t *testing.T) *rayv2.Ray {
+	hub := newTestRayV2(t, 1)
+	hub.Spec.RayVersion = "2.9.0"
+	hub.Spec.WorkerGroups[0].Name = "cpu"
+	hub.Spec.WorkerGroups[0].Autoscaling = &rayv2.AutoscalingSpec{MaxReplicas: 5}
+	return hub
+}
+
+func TestValidatingHandle(t *testing.T) {
+	cases := []struct {
+		name    string
+		version string
+		ray     runtime.Object
+		allowed bool
+	}{
+		{"valid v1", "v1", newTestRay(1), true},
+		{"invalid v1", "v1", newTestRay(2), false},
+		{"valid v2", "v2", newTestRayV2(t, 1), true},
+		{"invalid v2", "v2", newTestRayV2(t, 2), false},
+		{"v2 worker group autoscaling", "v2", newTestRayV2WithGroupAutoscaling(t), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v := newTestValidating()
+			if err := v.InjectDecoder(newTestDecoder(t)); err != nil {
+				t.Fatal(err)
+			}
+			resp := v.Handle(context.TODO(), newTestRequest(t, c.version, c.ray))
+			if resp.Allowed != c.allowed {
+				t.Errorf("expected allowed %v, got %v: %v", c.allowed, resp.Allowed, resp.Result)
+			}
+		})
+	}
+}
+
+func TestMutatingHandle(t *testing.T) {
+	m := &Mutating{}
+	if err := m.InjectDecoder(newTestDecoder(t)); err != nil {
+		t.Fatal(err)
+	}
+	ray := &rayv2.Ray{
+		TypeMeta: metav1.TypeMeta{APIVersion: rayv2.GroupVersion.String(), Kind: "Ray"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+	}
+	resp := m.Handle(context.TODO(), newTestRequest(t, "v2", ray))
+	if !resp.Allowed {
+		t.Fatalf("expected allowed, got %v", resp.Result)
+	}
+	if len(resp.Patches) == 0 {
+		t.Error("expected the defaults to be patched")
+	}
+}
+
+// newTestScaleRequest returns the admission request to scale the Ray to the replicas.
+func newTestScaleRequest(t *testing.T, ray *rayv1.Ray, replicas int32) admission.Request {
+	scale := &autoscalingv1.Scale{
+		TypeMeta:   metav1.TypeMeta{APIVersion: autoscalingv1.SchemeGroupVersion.String(), Kind: "Scale"},
+		ObjectMeta: metav1.ObjectMeta{Name: ray.Name, Namespace: ray.Namespace},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+	}
+	raw, err := json.Marshal(scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return admission.Request{AdmissionRequest: v1beta1.AdmissionRequest{
+		Kind:        metav1.GroupVersionKind{Group: "autoscaling", Version: "v1", Kind: "Scale"},
+		Name:        ray.Name,
+		Namespace:   ray.Namespace,
+		SubResource: "scale",
+		Operation:   v1beta1.Update,
+		Object:      runtime.RawExtension{Raw: raw},
+	}}
+}
+
+func TestValidatingHandleScale(t *testing.T) {
+	withGroups := newTestRay(1)
+	withGroups.Spec.WorkerGroups = []rayv1.WorkerGroupSpec{
+		{Name: "cpu", ReplicaSpec: withGroups.Spec.Worker},
+	}
+	withGroups.Spec.Worker = rayv1.ReplicaSpec{}
+
+	cases := []struct {
+		name     string
+		ray      *rayv1.Ray
+		replicas int32
+		allowed  bool
+	}{
+		{"worker", newTestRay(1), 10, true},
+		{"worker to zero", newTestRay(1), 0, false},
+		{"worker groups", withGroups, 10, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			_ = rayv1.AddToScheme(scheme)
+			v := newTestValidating()
+			v.Client = fake.NewFakeClientWithScheme(scheme, c.ray)
+			if err := v.InjectDecoder(newTestDecoder(t)); err != nil {
+				t.Fatal(err)
+			}
+			resp := v.Handle(context.TODO(), newTestScaleRequest(t, c.ray, c.replicas))
+			if resp.Allowed != c.allowed {
+				t.Errorf("expected allowed %v, got %v: %v", c.allowed, resp.Allowed, resp.Result)
+			}
+		})
+	}
+}